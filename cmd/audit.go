@@ -81,7 +81,7 @@ func (c auditCmd) Get(id string) (*models.V1AuditResponse, error) {
 	traces, err := c.client.Audit().FindAuditTraces(audit.NewFindAuditTracesParams().WithBody(&models.V1AuditFindRequest{
 		Rqid:  id,
 		Phase: viper.GetString("phase"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +131,7 @@ func (c auditCmd) List() ([]*models.V1AuditResponse, error) {
 		Error:        viper.GetString("error"),
 		StatusCode:   viper.GetInt32("status-code"),
 		Limit:        viper.GetInt64("limit"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/metal-stack/metalctl/cmd/completion"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCmd(c *config) *cobra.Command {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "manage the local caches of metalctl",
+		Long: `metalctl caches the results of shell completions per context on disk.
+The cache duration is configured with the completion_cache_ttl of a context, it defaults to 5m and 0s disables the cache.
+Cached completions of a resource are invalidated when the resource is modified through metalctl.`,
+	}
+
+	clearCmd := &cobra.Command{
+		Use:   "clear",
+		Short: "removes the cached shell completions of all contexts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := completion.ClearCache()
+			if err != nil {
+				return err
+			}
+
+			_, _ = fmt.Fprintln(c.out, "completion cache cleared")
+
+			return nil
+		},
+	}
+
+	cacheCmd.AddCommand(clearCmd)
+
+	return cacheCmd
+}
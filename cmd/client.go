@@ -0,0 +1,526 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	httptransport "github.com/go-openapi/runtime/client"
+	"github.com/go-openapi/strfmt"
+	metalgo "github.com/metal-stack/metal-go"
+	"github.com/metal-stack/metal-go/api/client"
+	"github.com/metal-stack/metal-go/api/client/audit"
+	"github.com/metal-stack/metal-go/api/client/filesystemlayout"
+	"github.com/metal-stack/metal-go/api/client/firewall"
+	"github.com/metal-stack/metal-go/api/client/firmware"
+	"github.com/metal-stack/metal-go/api/client/health"
+	"github.com/metal-stack/metal-go/api/client/image"
+	"github.com/metal-stack/metal-go/api/client/ip"
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/client/network"
+	"github.com/metal-stack/metal-go/api/client/partition"
+	"github.com/metal-stack/metal-go/api/client/project"
+	"github.com/metal-stack/metal-go/api/client/size"
+	"github.com/metal-stack/metal-go/api/client/sizeimageconstraint"
+	"github.com/metal-stack/metal-go/api/client/switch_operations"
+	"github.com/metal-stack/metal-go/api/client/tenant"
+	"github.com/metal-stack/metal-go/api/client/user"
+	"github.com/metal-stack/metal-go/api/client/version"
+	"github.com/metal-stack/metal-go/api/client/vpn"
+	"github.com/metal-stack/metal-lib/jwt/sec"
+	"github.com/metal-stack/metalctl/cmd/completion"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/metal-stack/security"
+)
+
+// newMetalClient creates the metal-go client, which sends its requests through the given transport and authenticates
+// them with the given authenticator
+func newMetalClient(driverURL string, transport http.RoundTripper, auth authenticator) (metalgo.Client, error) {
+	parsedURL, err := url.Parse(driverURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsedURL.Host == "" {
+		return nil, fmt.Errorf("invalid url:%s, must be in the form scheme://host[:port]/basepath", driverURL)
+	}
+
+	if auth != nil {
+		transport = &authTransport{next: transport, auth: auth}
+	}
+
+	rt := httptransport.New(parsedURL.Host, parsedURL.Path, []string{parsedURL.Scheme})
+	rt.Transport = transport
+
+	return &metalClient{c: client.New(rt, strfmt.Default)}, nil
+}
+
+// metalClient implements the metal-go client on top of a runtime with a custom transport,
+// which metal-go does not provide an option for
+type metalClient struct {
+	c *client.MetalAPI
+}
+
+func (m *metalClient) Audit() audit.ClientService {
+	return m.c.Audit
+}
+func (m *metalClient) Filesystemlayout() filesystemlayout.ClientService {
+	return m.c.Filesystemlayout
+}
+func (m *metalClient) Firewall() firewall.ClientService {
+	return m.c.Firewall
+}
+func (m *metalClient) Firmware() firmware.ClientService {
+	return m.c.Firmware
+}
+func (m *metalClient) Health() health.ClientService {
+	return m.c.Health
+}
+func (m *metalClient) Image() image.ClientService {
+	return m.c.Image
+}
+func (m *metalClient) IP() ip.ClientService {
+	return m.c.IP
+}
+func (m *metalClient) Machine() machine.ClientService {
+	return m.c.Machine
+}
+func (m *metalClient) Network() network.ClientService {
+	return m.c.Network
+}
+func (m *metalClient) Partition() partition.ClientService {
+	return m.c.Partition
+}
+func (m *metalClient) Project() project.ClientService {
+	return m.c.Project
+}
+func (m *metalClient) Size() size.ClientService {
+	return m.c.Size
+}
+func (m *metalClient) Sizeimageconstraint() sizeimageconstraint.ClientService {
+	return m.c.Sizeimageconstraint
+}
+func (m *metalClient) SwitchOperations() switch_operations.ClientService {
+	return m.c.SwitchOperations
+}
+func (m *metalClient) Tenant() tenant.ClientService {
+	return m.c.Tenant
+}
+func (m *metalClient) User() user.ClientService {
+	return m.c.User
+}
+func (m *metalClient) Version() version.ClientService {
+	return m.c.Version
+}
+func (m *metalClient) VPN() vpn.ClientService {
+	return m.c.Vpn
+}
+
+// authenticator adds the credentials to requests against the metal-api
+type authenticator interface {
+	authenticate(r *http.Request) error
+}
+
+type authenticatorFunc func(r *http.Request) error
+
+func (f authenticatorFunc) authenticate(r *http.Request) error {
+	return f(r)
+}
+
+// authTransport authenticates every request, it is the outermost transport such that
+// the inner ones like tracing and refreshing see the credentials
+type authTransport struct {
+	next http.RoundTripper
+	auth authenticator
+}
+
+func (t *authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	// a round tripper must not modify the given request
+	r = r.Clone(r.Context())
+
+	err := t.auth.authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.next.RoundTrip(r)
+}
+
+// bearerToken holds the token used for authenticating against the metal-api,
+// the token can be replaced by a refreshed one during the lifetime of the client
+type bearerToken struct {
+	mu    sync.Mutex
+	token string
+
+	// refresh obtains a new token, nil if refreshing is disabled
+	refresh func(ctx context.Context) (string, error)
+	// refreshErr is the error of refreshing the current token, which is not refreshed again then
+	refreshErr error
+}
+
+func (b *bearerToken) get() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.token
+}
+
+// set replaces the token and the function for refreshing it
+func (b *bearerToken) set(token string, refresh func(ctx context.Context) (string, error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if token != b.token {
+		b.refreshErr = nil
+	}
+	b.token = token
+	b.refresh = refresh
+}
+
+// renew refreshes the rejected token. Every token is refreshed at most once, if it was already replaced
+// the current token is returned.
+func (b *bearerToken) renew(ctx context.Context, rejected string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.refresh == nil {
+		return "", fmt.Errorf("token refresh is disabled")
+	}
+	if b.token != rejected {
+		return b.token, nil
+	}
+	if b.refreshErr != nil {
+		return "", b.refreshErr
+	}
+
+	token, err := b.refresh(ctx)
+	if err != nil {
+		b.refreshErr = err
+		return "", err
+	}
+	b.token = token
+
+	return token, nil
+}
+
+func (b *bearerToken) authenticate(r *http.Request) error {
+	r.Header.Set("Authorization", "Bearer "+b.get())
+	return nil
+}
+
+func hmacAuthenticator(authType, key string) authenticator {
+	hmacAuth := security.NewHMACAuth(authType, []byte(key))
+	return authenticatorFunc(func(r *http.Request) error {
+		for k, v := range hmacAuth.AuthHeaders(r.Method, time.Now()) {
+			r.Header.Set(k, v)
+		}
+		return nil
+	})
+}
+
+// credentialIdentity describes the credentials of the authenticator, such that completions retrieved with the
+// permissions of one identity are not shown for another one
+func credentialIdentity(auth authenticator, hmacAuthType string) string {
+	switch a := auth.(type) {
+	case nil:
+		return ""
+	case *credentialHelperAuth:
+		return "credential-helper:" + strings.Join(append([]string{a.helper.Command}, a.helper.Args...), " ")
+	case *bearerToken:
+		token := a.get()
+		if _, claims, err := sec.ParseTokenUnvalidatedUnfiltered(token); err == nil && claims.Subject != "" {
+			return "token:" + claims.Issuer + " " + claims.Subject
+		}
+		return "token:" + token
+	default:
+		return "hmac:" + hmacAuthType
+	}
+}
+
+// credentialHelperAuth authenticates requests with the hmac or token of the credential helper, which is run with the
+// first request and again when the credentials expire or the token is rejected. Helpers, which only supply a client
+// secret, fall back to the authentication of the context.
+type credentialHelperAuth struct {
+	helper       *api.CredentialHelper
+	hmacAuthType string
+	token        *bearerToken
+	fallback     authenticator
+
+	mu  sync.Mutex
+	err error
+}
+
+func (a *credentialHelperAuth) authenticate(r *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// a failing helper is not run again for every request
+	if a.err != nil {
+		return a.err
+	}
+
+	// the credentials are kept in memory until they expire
+	creds, err := a.helper.Credentials()
+	if err != nil {
+		a.err = err
+		return err
+	}
+
+	switch {
+	case creds.HMAC != "":
+		return hmacAuthenticator(a.hmacAuthType, creds.HMAC).authenticate(r)
+	case creds.Token != "":
+		a.token.set(creds.Token, a.refresh)
+		return a.token.authenticate(r)
+	case a.fallback != nil:
+		return a.fallback.authenticate(r)
+	default:
+		return nil
+	}
+}
+
+// refresh runs the helper again for a token rejected by the metal-api
+func (a *credentialHelperAuth) refresh(_ context.Context) (string, error) {
+	creds, err := a.helper.Refresh()
+	if err != nil {
+		return "", err
+	}
+	if creds.Token == "" {
+		return "", fmt.Errorf("credential helper %q returned no token", a.helper.Command)
+	}
+	return creds.Token, nil
+}
+
+// refreshTransport refreshes the bearer token when the metal-api responds with unauthorized
+// and retries the request once with the new token
+type refreshTransport struct {
+	next  http.RoundTripper
+	token *bearerToken
+	log   *slog.Logger
+}
+
+func (t *refreshTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	rejected, usesBearer := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	if usesBearer {
+		// the body needs to be replayable for the retry
+		err := makeBodyReplayable(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := t.next.RoundTrip(r)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || !usesBearer {
+		return resp, err
+	}
+
+	token, err := t.token.renew(r.Context(), rejected)
+	if err != nil {
+		t.log.Debug("unable to refresh token", "error", err)
+		return resp, nil
+	}
+
+	retry := r.Clone(r.Context())
+	if r.GetBody != nil {
+		retry.Body, err = r.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+	}
+	retry.Header.Set("Authorization", "Bearer "+token)
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	return t.next.RoundTrip(retry)
+}
+
+// retryTransport retries idempotent requests on transient errors with an exponential backoff
+type retryTransport struct {
+	next   http.RoundTripper
+	policy api.RetryPolicy
+	log    *slog.Logger
+
+	// auth authenticates every retry again, as hmac signatures are only valid for a short time
+	auth authenticator
+}
+
+func (t *retryTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if t.policy.MaxRetries <= 0 || !isIdempotent(r) {
+		return t.next.RoundTrip(r)
+	}
+
+	err := makeBodyReplayable(r)
+	if err != nil {
+		return nil, err
+	}
+
+	req := r
+	for retry := 0; ; retry++ {
+		resp, err := t.next.RoundTrip(req)
+		if retry >= t.policy.MaxRetries || !isTransient(r.Context(), resp, err) {
+			return resp, err
+		}
+
+		backoff := t.policy.Backoff(retry)
+		var reason string
+		if err != nil {
+			reason = err.Error()
+		} else {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				backoff = min(retryAfter, t.policy.MaxBackoff)
+			}
+			reason = resp.Status
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		t.log.Debug("retrying request", "method", r.Method, "url", r.URL.String(), "retry", retry+1, "reason", reason, "backoff", backoff)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+
+		req = r.Clone(r.Context())
+		if r.GetBody != nil {
+			req.Body, err = r.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if t.auth != nil {
+			err = t.auth.authenticate(req)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// cacheInvalidationTransport removes the cached completions of the resources modified by a request,
+// such that completions reflect changes made with metalctl before the cache expires
+type cacheInvalidationTransport struct {
+	next  http.RoundTripper
+	cache *completion.Cache
+	log   *slog.Logger
+}
+
+func (t *cacheInvalidationTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if !isIdempotent(r) {
+		if resource := completion.ResourceFromPath(r.URL.Path); resource != "" {
+			err := t.cache.Invalidate(resource)
+			if err != nil {
+				t.log.Debug("unable to invalidate completion cache", "resource", resource, "error", err)
+			}
+		}
+	}
+
+	return t.next.RoundTrip(r)
+}
+
+// readOnlyPostPaths are operations of the metal-api which use POST without modifying anything
+var readOnlyPostPaths = []string{
+	"/v1/filesystemlayout/matches",
+	"/v1/filesystemlayout/try",
+	"/v1/machine/issues/evaluate",
+	"/v1/partition/capacity",
+	"/v1/size-image-constraint/try",
+	"/v1/size/reservations/usage",
+	"/v1/size/suggest",
+}
+
+// isIdempotent returns true for requests which can safely be sent multiple times,
+// besides gets these are the finds and other queries of the metal-api which are sent as POST
+func isIdempotent(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		if strings.HasSuffix(r.URL.Path, "/find") {
+			return true
+		}
+		for _, path := range readOnlyPostPaths {
+			if strings.HasSuffix(r.URL.Path, path) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isTransient returns true for errors, which may disappear on their own like unavailable or overloaded servers,
+// in contrast to errors like invalid certificates or unknown hosts
+func isTransient(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return isTransientNetworkError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func isTransientNetworkError(err error) bool {
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET):
+		return true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		// the connection was closed by the server
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// parseRetryAfter parses the Retry-After header, which is either given in seconds or as http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+	return 0, false
+}
+
+// makeBodyReplayable buffers the request body, so it can be sent again
+func makeBodyReplayable(r *http.Request) error {
+	if r.Body == nil || r.GetBody != nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	_ = r.Body.Close()
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return nil
+}
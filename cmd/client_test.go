@@ -0,0 +1,527 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	metalgo "github.com/metal-stack/metal-go"
+	"github.com/metal-stack/metal-go/api/client/ip"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metalctl/cmd/completion"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newMetalClient(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client, err := newMetalClient(server.URL, roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return http.DefaultTransport.RoundTrip(r)
+	}), &bearerToken{token: "token"})
+	require.NoError(t, err)
+
+	_, err = client.IP().ListIPs(ip.NewListIPsParams(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "requests must be sent through the given transport")
+
+	_, err = newMetalClient("no-url", http.DefaultTransport, nil)
+	require.EqualError(t, err, "invalid url:no-url, must be in the form scheme://host[:port]/basepath")
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func Test_refreshTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		refresh     func(ctx context.Context) (string, error)
+		wantErr     bool
+		wantCalls   int
+		wantRefresh int
+	}{
+		{
+			name: "retries with refreshed token",
+			refresh: func(ctx context.Context) (string, error) {
+				return "fresh", nil
+			},
+			wantCalls:   2,
+			wantRefresh: 1,
+		},
+		{
+			name: "failing refresh returns unauthorized",
+			refresh: func(ctx context.Context) (string, error) {
+				return "", fmt.Errorf("no refresh token")
+			},
+			wantErr:     true,
+			wantCalls:   1,
+			wantRefresh: 1,
+		},
+		{
+			name:      "refresh disabled",
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				calls   int
+				refresh int
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"ipaddress":"1.2.3.4","networkid":"internet","projectid":"p","tags":null,"type":"ephemeral"}`, string(body))
+
+				if r.Header.Get("Authorization") != "Bearer fresh" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`[{"ipaddress":"1.2.3.4"}]`))
+			}))
+			defer server.Close()
+
+			token := &bearerToken{token: "expired"}
+			if tt.refresh != nil {
+				token.refresh = func(ctx context.Context) (string, error) {
+					refresh++
+					return tt.refresh(ctx)
+				}
+			}
+
+			client, err := newMetalClient(server.URL, &refreshTransport{
+				next:  http.DefaultTransport,
+				token: token,
+				log:   slog.New(slog.DiscardHandler),
+			}, token)
+			require.NoError(t, err)
+
+			_, err = client.IP().FindIPs(ip.NewFindIPsParams().WithBody(&models.V1IPFindRequest{
+				Ipaddress: "1.2.3.4",
+				Networkid: "internet",
+				Projectid: "p",
+				Type:      "ephemeral",
+			}), nil)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Equal(t, tt.wantRefresh, refresh)
+		})
+	}
+}
+
+func Test_credentialHelperAuth(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo run >> "$1"
+echo "$2"
+`), 0700))
+
+	tests := []struct {
+		name       string
+		output     string
+		wantHeader string
+		wantErr    bool
+	}{
+		{
+			name:       "token",
+			output:     `{"token": "helper-token"}`,
+			wantHeader: "Bearer helper-token",
+		},
+		{
+			name:       "client secret only falls back",
+			output:     `{"client_secret": "secret"}`,
+			wantHeader: "Bearer kubeconfig-token",
+		},
+		{
+			name:    "failing helper",
+			output:  `no json`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := filepath.Join(t.TempDir(), "runs")
+			token := &bearerToken{token: "kubeconfig-token"}
+
+			auth := &credentialHelperAuth{
+				helper:   &api.CredentialHelper{Command: script, Args: []string{runs, tt.output}},
+				token:    token,
+				fallback: token,
+			}
+
+			require.NoFileExists(t, runs, "the helper must not run before the first request")
+
+			var headers []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				headers = append(headers, r.Header.Get("Authorization"))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			client, err := newMetalClient(server.URL, http.DefaultTransport, auth)
+			require.NoError(t, err)
+
+			for range 2 {
+				_, err = client.IP().ListIPs(ip.NewListIPsParams(), nil)
+				if tt.wantErr {
+					require.Error(t, err)
+				} else {
+					require.NoError(t, err)
+				}
+			}
+
+			got, err := os.ReadFile(runs)
+			require.NoError(t, err)
+			assert.Equal(t, "run\n", string(got), "the helper must run only once")
+
+			if !tt.wantErr {
+				assert.Equal(t, []string{tt.wantHeader, tt.wantHeader}, headers)
+			}
+		})
+	}
+}
+
+func Test_credentialHelperAuth_Renew(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "helper.sh")
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo run >> "$1"
+echo "$2"
+`), 0700))
+
+	tests := []struct {
+		name     string
+		output   string
+		statuses []int
+		wantRuns string
+	}{
+		{
+			name:     "expired credentials are resolved again",
+			output:   `{"token": "helper-token", "expiration": "2020-01-01T00:00:00Z"}`,
+			statuses: []int{http.StatusOK, http.StatusOK},
+			wantRuns: "run\nrun\n",
+		},
+		{
+			name:     "rejected token runs the helper again",
+			output:   `{"token": "helper-token"}`,
+			statuses: []int{http.StatusUnauthorized, http.StatusOK, http.StatusOK},
+			wantRuns: "run\nrun\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs := filepath.Join(t.TempDir(), "runs")
+			token := &bearerToken{}
+
+			auth := &credentialHelperAuth{
+				helper: &api.CredentialHelper{Command: script, Args: []string{runs, tt.output}},
+				token:  token,
+			}
+
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "Bearer helper-token", r.Header.Get("Authorization"))
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.statuses[calls])
+				calls++
+				_, _ = w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			client, err := newMetalClient(server.URL, &refreshTransport{
+				next:  http.DefaultTransport,
+				token: token,
+				log:   slog.New(slog.DiscardHandler),
+			}, auth)
+			require.NoError(t, err)
+
+			for range 2 {
+				_, err = client.IP().ListIPs(ip.NewListIPsParams(), nil)
+				require.NoError(t, err)
+			}
+
+			got, err := os.ReadFile(runs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRuns, string(got))
+			assert.Equal(t, len(tt.statuses), calls)
+		})
+	}
+}
+
+func Test_retryTransport(t *testing.T) {
+	tests := []struct {
+		name       string
+		call       func(client metalgo.Client) error
+		responses  []int
+		retryAfter string
+		wantErr    bool
+		wantCalls  int
+	}{
+		{
+			name: "find is retried",
+			call: func(client metalgo.Client) error {
+				_, err := client.IP().FindIPs(ip.NewFindIPsParams().WithBody(&models.V1IPFindRequest{Projectid: "p"}), nil)
+				return err
+			},
+			responses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			wantCalls: 3,
+		},
+		{
+			name: "get is retried",
+			call: func(client metalgo.Client) error {
+				_, err := client.IP().ListIPs(ip.NewListIPsParams(), nil)
+				return err
+			},
+			responses:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			retryAfter: "0",
+			wantCalls:  2,
+		},
+		{
+			name: "retry after is limited to the max backoff",
+			call: func(client metalgo.Client) error {
+				_, err := client.IP().ListIPs(ip.NewListIPsParams(), nil)
+				return err
+			},
+			responses:  []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "3600",
+			wantCalls:  2,
+		},
+		{
+			name: "gives up after max retries",
+			call: func(client metalgo.Client) error {
+				_, err := client.IP().ListIPs(ip.NewListIPsParams(), nil)
+				return err
+			},
+			responses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			wantErr:   true,
+			wantCalls: 4,
+		},
+		{
+			name: "allocation is not retried",
+			call: func(client metalgo.Client) error {
+				_, err := client.IP().AllocateIP(ip.NewAllocateIPParams().WithBody(&models.V1IPAllocateRequest{Projectid: new("p"), Networkid: new("internet")}), nil)
+				return err
+			},
+			responses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+		{
+			name: "client errors are not retried",
+			call: func(client metalgo.Client) error {
+				_, err := client.IP().ListIPs(ip.NewListIPsParams(), nil)
+				return err
+			},
+			responses: []int{http.StatusNotFound, http.StatusOK},
+			wantErr:   true,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tt.responses[calls]
+				calls++
+
+				assert.Equal(t, strconv.Itoa(calls), r.Header.Get("Authorization"), "every attempt must be authenticated again")
+
+				if r.Method == http.MethodPost {
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(t, err)
+					assert.NotEmpty(t, body)
+				}
+
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				if status != http.StatusOK {
+					w.WriteHeader(status)
+					return
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/allocate") {
+					_, _ = w.Write([]byte(`{"ipaddress":"1.2.3.4"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`[{"ipaddress":"1.2.3.4"}]`))
+			}))
+			defer server.Close()
+
+			var authentications int
+			auth := authenticatorFunc(func(r *http.Request) error {
+				authentications++
+				r.Header.Set("Authorization", strconv.Itoa(authentications))
+				return nil
+			})
+
+			client, err := newMetalClient(server.URL, &retryTransport{
+				next:   http.DefaultTransport,
+				policy: api.RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond},
+				log:    slog.New(slog.DiscardHandler),
+				auth:   auth,
+			}, auth)
+			require.NoError(t, err)
+
+			err = tt.call(client)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
+
+func Test_cacheInvalidationTransport(t *testing.T) {
+	cacheHome := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+	cache, err := completion.NewCache("prod", "http://metal-api", "", time.Minute)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		method          string
+		path            string
+		wantInvalidated []string
+	}{
+		{method: http.MethodGet, path: "/v1/machine/m1"},
+		{method: http.MethodPost, path: "/v1/machine/find"},
+		{method: http.MethodPost, path: "/v1/firewall/allocate", wantInvalidated: []string{"machine"}},
+		{method: http.MethodDelete, path: "/v1/network/n1", wantInvalidated: []string{"network"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			resources := []string{"machine", "network"}
+			for _, resource := range resources {
+				dir := filepath.Join(cacheHome, "metalctl", "completion", "prod", resource)
+				require.NoError(t, os.MkdirAll(dir, 0700))
+				require.NoError(t, os.WriteFile(filepath.Join(dir, "entry.json"), []byte("{}"), 0600))
+			}
+
+			transport := &cacheInvalidationTransport{
+				next:  http.DefaultTransport,
+				cache: cache,
+				log:   slog.New(slog.DiscardHandler),
+			}
+
+			req, err := http.NewRequest(tt.method, server.URL+tt.path, nil)
+			require.NoError(t, err)
+			resp, err := transport.RoundTrip(req)
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+
+			for _, resource := range resources {
+				_, err := os.Stat(filepath.Join(cacheHome, "metalctl", "completion", "prod", resource))
+				assert.Equal(t, slices.Contains(tt.wantInvalidated, resource), os.IsNotExist(err), "resource %s", resource)
+			}
+		})
+	}
+}
+
+func Test_isTransient(t *testing.T) {
+	ok := &http.Response{StatusCode: http.StatusOK}
+
+	tests := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{name: "connection refused", err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}, want: true},
+		{name: "connection reset", err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, want: true},
+		{name: "timeout", err: &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}, want: true},
+		{name: "connection closed", err: io.ErrUnexpectedEOF, want: true},
+		{name: "unknown host", err: &net.OpError{Op: "dial", Err: &net.DNSError{Err: "no such host", Name: "metal.test", IsNotFound: true}}},
+		{name: "invalid certificate", err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}},
+		{name: "service unavailable", resp: &http.Response{StatusCode: http.StatusServiceUnavailable}, want: true},
+		{name: "ok", resp: ok},
+		{name: "not found", resp: &http.Response{StatusCode: http.StatusNotFound}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isTransient(context.Background(), tt.resp, tt.err))
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(t, isTransient(ctx, nil, &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}), "canceled requests must not be retried")
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	got, ok := parseRetryAfter("2")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, got)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+
+	got, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), got)
+}
+
+func Test_credentialIdentity(t *testing.T) {
+	userToken := mustSignToken(t, map[string]any{"iss": "https://dex", "sub": "user"})
+	refreshedToken := mustSignToken(t, map[string]any{"iss": "https://dex", "sub": "user", "exp": time.Now().Add(time.Hour).Unix()})
+	otherToken := mustSignToken(t, map[string]any{"iss": "https://dex", "sub": "other"})
+
+	assert.Empty(t, credentialIdentity(nil, ""))
+	assert.Equal(t, "hmac:Metal-View", credentialIdentity(hmacAuthenticator("Metal-View", "secret"), "Metal-View"))
+	assert.Equal(t, "token:https://dex user", credentialIdentity(&bearerToken{token: userToken}, ""))
+	assert.Equal(t, credentialIdentity(&bearerToken{token: userToken}, ""), credentialIdentity(&bearerToken{token: refreshedToken}, ""), "a refreshed token keeps the identity")
+	assert.NotEqual(t, credentialIdentity(&bearerToken{token: userToken}, ""), credentialIdentity(&bearerToken{token: otherToken}, ""))
+	assert.Equal(t, "token:opaque", credentialIdentity(&bearerToken{token: "opaque"}, ""))
+	assert.Equal(t, "credential-helper:vault read metal", credentialIdentity(&credentialHelperAuth{helper: &api.CredentialHelper{Command: "vault", Args: []string{"read", "metal"}}}, ""))
+}
@@ -60,6 +60,7 @@ type test[R any] struct {
 	template      *string // for template printer
 	wantTemplate  *string // for template printer
 	wantMarkdown  *string // for markdown printer
+	wantCSV       *string // for csv printer
 }
 
 func (c *test[R]) testCmd(t *testing.T) {
@@ -113,7 +114,7 @@ func (c *test[R]) newMockConfig(t *testing.T) (*client.MetalMockClient, *bytes.B
 			fs:     fs,
 			client: client,
 			out:    &out,
-			log:    slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{})),
+			log:    slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{})),
 			comp:   &completion.Completion{},
 		}
 	)
@@ -193,6 +194,10 @@ func outputFormats[R any](c *test[R]) []outputFormat[R] {
 		formats = append(formats, &markdownOutputFormat[R]{table: *c.wantMarkdown})
 	}
 
+	if c.wantCSV != nil {
+		formats = append(formats, &csvOutputFormat[R]{csv: *c.wantCSV})
+	}
+
 	return formats
 }
 
@@ -290,6 +295,22 @@ func (o *markdownOutputFormat[R]) Validate(t *testing.T, output []byte) {
 	validateTableRows(t, o.table, string(output))
 }
 
+type csvOutputFormat[R any] struct {
+	csv string
+}
+
+func (o *csvOutputFormat[R]) Args() []string {
+	return []string{"-o", "csv"}
+}
+
+func (o *csvOutputFormat[R]) Validate(t *testing.T, output []byte) {
+	t.Logf("got following csv output:\n\n%s\n\nconsider using this for test comparison if it looks correct.", string(output))
+
+	if diff := cmp.Diff(strings.TrimSpace(o.csv), strings.TrimSpace(string(output))); diff != "" {
+		t.Errorf("diff (+got -want):\n %s", diff)
+	}
+}
+
 func validateTableRows(t *testing.T, want, got string) {
 	trimAll := func(ss []string) []string {
 		var res []string
@@ -344,7 +365,7 @@ func commonExcludedFileArgs() []string {
 // 			fs:     afero.NewOsFs(),
 // 			client: client,
 // 			out:    &out,
-// 			log:    slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{})),
+// 			log:    slog.New(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{})),
 // 			comp:   &completion.Completion{},
 // 		}
 // 	)
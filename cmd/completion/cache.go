@@ -0,0 +1,156 @@
+package completion
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Cache stores completion results on disk per context, such that repeated completions
+// do not need to query the metal-api. The results are stored per resource of the metal-api,
+// which allows invalidating them when the resource gets modified. Results are only returned
+// for the same api and credentials they were retrieved with, as they depend on the permissions.
+type Cache struct {
+	dir      string
+	url      string
+	identity string
+	ttl      time.Duration
+}
+
+type cacheEntry struct {
+	URL      string    `json:"url"`
+	Identity string    `json:"identity"`
+	Created  time.Time `json:"created"`
+	Values   []string  `json:"values"`
+}
+
+// NewCache returns the completion cache of the given context, a zero ttl disables the cache.
+// The identity describes the credentials, it is only stored as hash.
+func NewCache(contextName, url, identity string, ttl time.Duration) (*Cache, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	if contextName == "" {
+		contextName = "default"
+	}
+
+	identityHash := sha256.Sum256([]byte(identity))
+
+	return &Cache{
+		dir:      filepath.Join(dir, contextName),
+		url:      url,
+		identity: hex.EncodeToString(identityHash[:]),
+		ttl:      ttl,
+	}, nil
+}
+
+// ClearCache removes the completion caches of all contexts
+func ClearCache() error {
+	dir, err := cacheDir()
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// Invalidate removes the cached completions of the given resource
+func (c *Cache) Invalidate(resource string) error {
+	if c == nil {
+		return nil
+	}
+	return os.RemoveAll(filepath.Join(c.dir, resource))
+}
+
+func (c *Cache) get(resource, key string) ([]string, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, false
+	}
+
+	raw, err := os.ReadFile(c.path(resource, key))
+	if err != nil {
+		return nil, false
+	}
+
+	var entry cacheEntry
+	err = json.Unmarshal(raw, &entry)
+	if err != nil || entry.URL != c.url || entry.Identity != c.identity || time.Since(entry.Created) > c.ttl {
+		return nil, false
+	}
+
+	return entry.Values, true
+}
+
+func (c *Cache) put(resource, key string, values []string) {
+	if c == nil || c.ttl <= 0 {
+		return
+	}
+
+	raw, err := json.Marshal(cacheEntry{
+		URL:      c.url,
+		Identity: c.identity,
+		Created:  time.Now(),
+		Values:   values,
+	})
+	if err != nil {
+		return
+	}
+
+	path := c.path(resource, key)
+
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return
+	}
+
+	// a temporary file prevents concurrent completions from reading partially written entries
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".entry-*")
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.Write(raw)
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+
+	_ = os.Rename(tmp.Name(), path)
+}
+
+func (c *Cache) path(resource, key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, resource, hex.EncodeToString(hash[:8])+".json")
+}
+
+func cacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to figure out user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "metalctl", "completion"), nil
+}
+
+// ResourceFromPath returns the resource of the metal-api the given request path refers to,
+// firewalls are machines and therefore share their completions
+func ResourceFromPath(path string) string {
+	_, rest, ok := strings.Cut(path, "/v1/")
+	if !ok {
+		return ""
+	}
+
+	resource, _, _ := strings.Cut(rest, "/")
+	if resource == "firewall" {
+		return "machine"
+	}
+
+	return resource
+}
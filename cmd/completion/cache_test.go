@@ -0,0 +1,92 @@
+package completion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cache, err := NewCache("prod", "https://api.metal-stack.io/metal", "token:user", time.Minute)
+	require.NoError(t, err)
+
+	_, ok := cache.get("machine", "MachineListCompletion")
+	assert.False(t, ok, "empty cache must not return completions")
+
+	cache.put("machine", "MachineListCompletion", []string{"m1", "m2"})
+	cache.put("image", "ImageListCompletion", []string{"ubuntu-24.04"})
+
+	values, ok := cache.get("machine", "MachineListCompletion")
+	require.True(t, ok)
+	assert.Equal(t, []string{"m1", "m2"}, values)
+
+	other, err := NewCache("prod", "https://api.metal-stack.dev/metal", "token:user", time.Minute)
+	require.NoError(t, err)
+	_, ok = other.get("machine", "MachineListCompletion")
+	assert.False(t, ok, "completions of another api url must not be returned")
+
+	other, err = NewCache("dev", "https://api.metal-stack.io/metal", "token:user", time.Minute)
+	require.NoError(t, err)
+	_, ok = other.get("machine", "MachineListCompletion")
+	assert.False(t, ok, "completions of another context must not be returned")
+
+	other, err = NewCache("prod", "https://api.metal-stack.io/metal", "token:other-user", time.Minute)
+	require.NoError(t, err)
+	_, ok = other.get("machine", "MachineListCompletion")
+	assert.False(t, ok, "completions of other credentials must not be returned")
+
+	expired, err := NewCache("prod", "https://api.metal-stack.io/metal", "token:user", time.Nanosecond)
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond)
+	_, ok = expired.get("machine", "MachineListCompletion")
+	assert.False(t, ok, "expired completions must not be returned")
+
+	require.NoError(t, cache.Invalidate("machine"))
+	_, ok = cache.get("machine", "MachineListCompletion")
+	assert.False(t, ok, "invalidated completions must not be returned")
+	_, ok = cache.get("image", "ImageListCompletion")
+	assert.True(t, ok, "completions of other resources must be kept")
+
+	require.NoError(t, ClearCache())
+	_, ok = cache.get("image", "ImageListCompletion")
+	assert.False(t, ok, "cleared completions must not be returned")
+}
+
+func TestCache_Disabled(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cache, err := NewCache("prod", "https://api.metal-stack.io/metal", "token:user", 0)
+	require.NoError(t, err)
+
+	cache.put("machine", "MachineListCompletion", []string{"m1"})
+	_, ok := cache.get("machine", "MachineListCompletion")
+	assert.False(t, ok)
+
+	var nilCache *Cache
+	nilCache.put("machine", "MachineListCompletion", []string{"m1"})
+	_, ok = nilCache.get("machine", "MachineListCompletion")
+	assert.False(t, ok)
+	assert.NoError(t, nilCache.Invalidate("machine"))
+}
+
+func TestResourceFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/metal/v1/machine/allocate", want: "machine"},
+		{path: "/metal/v1/firewall/allocate", want: "machine"},
+		{path: "/v1/switch/leaf01", want: "switch"},
+		{path: "/metal/v1/network", want: "network"},
+		{path: "/metal/healthz", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			assert.Equal(t, tt.want, ResourceFromPath(tt.path))
+		})
+	}
+}
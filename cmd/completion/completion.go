@@ -7,12 +7,34 @@ import (
 
 type Completion struct {
 	client metalgo.Client
+	cache  *Cache
 }
 
 func (c *Completion) SetClient(client metalgo.Client) {
 	c.client = client
 }
 
+func (c *Completion) SetCache(cache *Cache) {
+	c.cache = cache
+}
+
+// cached returns the completions of the given resource from the completion cache,
+// fetch is only called if there are no valid cached completions
+func (c *Completion) cached(resource, key string, fetch func() ([]string, error)) ([]string, cobra.ShellCompDirective) {
+	if names, ok := c.cache.get(resource, key); ok {
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names, err := fetch()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	c.cache.put(resource, key, names)
+
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
 func OutputFormatListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return []string{"table", "wide", "markdown", "json", "yaml", "template"}, cobra.ShellCompDirectiveNoFileComp
+	return []string{"table", "wide", "markdown", "json", "yaml", "template", "csv", "tsv", "jsonpath=", "custom-columns=", "custom-columns-markdown="}, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
 }
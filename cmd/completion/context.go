@@ -16,3 +16,28 @@ func (c *Completion) ContextListCompletion(cmd *cobra.Command, args []string, to
 	}
 	return names, cobra.ShellCompDirectiveNoFileComp
 }
+
+// ContextRenameCompletion completes the context to rename, the new name is not completed
+func (c *Completion) ContextRenameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return c.ContextListCompletion(cmd, args, toComplete)
+}
+
+func (c *Completion) ContextFieldCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return c.ContextListCompletion(cmd, args, toComplete)
+	case 1:
+		return api.ContextFields, cobra.ShellCompDirectiveNoFileComp
+	case 2:
+		switch args[1] {
+		case "issuer_type":
+			return api.IssuerTypes, cobra.ShellCompDirectiveNoFileComp
+		case "hmac_auth_type":
+			return api.HMACAuthTypes, cobra.ShellCompDirectiveNoFileComp
+		}
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
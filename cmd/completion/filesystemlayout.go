@@ -6,7 +6,7 @@ import (
 )
 
 func (c *Completion) FilesystemLayoutListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Filesystemlayout().ListFilesystemLayouts(filesystemlayout.NewListFilesystemLayoutsParams(), nil)
+	resp, err := c.client.Filesystemlayout().ListFilesystemLayouts(filesystemlayout.NewListFilesystemLayoutsParams().WithContext(cmd.Context()), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
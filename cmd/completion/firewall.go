@@ -6,7 +6,7 @@ import (
 )
 
 func (c *Completion) FirewallListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Firewall().ListFirewalls(firewall.NewListFirewallsParams(), nil)
+	resp, err := c.client.Firewall().ListFirewalls(firewall.NewListFirewallsParams().WithContext(cmd.Context()), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -1,6 +1,8 @@
 package completion
 
 import (
+	"context"
+
 	"github.com/metal-stack/metal-go/api/client/firmware"
 	"github.com/metal-stack/metal-go/api/client/machine"
 	"github.com/metal-stack/metal-go/api/models"
@@ -12,7 +14,7 @@ func (c *Completion) FirmwareKindCompletion(cmd *cobra.Command, args []string, t
 }
 
 func (c *Completion) FirmwareVendorCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams(), nil)
+	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithContext(cmd.Context()), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -27,7 +29,7 @@ func (c *Completion) FirmwareVendorCompletion(cmd *cobra.Command, args []string,
 }
 
 func (c *Completion) FirmwareBoardCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams(), nil)
+	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithContext(cmd.Context()), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -44,35 +46,35 @@ func (c *Completion) FirmwareBoardCompletion(cmd *cobra.Command, args []string,
 }
 
 func (c *Completion) FirmwareRevisionCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	return c.firmwareRevisions("", "")
+	return c.firmwareRevisions(cmd.Context(), "", "")
 }
 
 func (c *Completion) FirmwareBiosRevisionCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) != 1 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	return c.firmwareRevisions(args[0], models.V1MachineUpdateFirmwareRequestKindBios)
+	return c.firmwareRevisions(cmd.Context(), args[0], models.V1MachineUpdateFirmwareRequestKindBios)
 }
 
 func (c *Completion) FirmwareBmcRevisionCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	if len(args) != 1 {
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	return c.firmwareRevisions(args[0], models.V1MachineUpdateFirmwareRequestKindBmc)
+	return c.firmwareRevisions(cmd.Context(), args[0], models.V1MachineUpdateFirmwareRequestKindBmc)
 }
 
-func (c *Completion) firmwareRevisions(machineID string, kind string) ([]string, cobra.ShellCompDirective) {
+func (c *Completion) firmwareRevisions(ctx context.Context, machineID string, kind string) ([]string, cobra.ShellCompDirective) {
 	vendor := ""
 	board := ""
 	if machineID != "" {
-		m, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(machineID), nil)
+		m, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(machineID).WithContext(ctx), nil)
 		if err != nil {
 			return nil, cobra.ShellCompDirectiveError
 		}
 		board = m.Payload.Ipmi.Fru.BoardPartNumber
 		vendor = m.Payload.Ipmi.Fru.BoardMfg
 	}
-	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithKind(&kind).WithVendor(&vendor).WithBoard(&board), nil)
+	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithKind(&kind).WithVendor(&vendor).WithBoard(&board).WithContext(ctx), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -22,66 +22,74 @@ func (c *Completion) ImageFeatureCompletion(cmd *cobra.Command, args []string, t
 }
 
 func (c *Completion) ImageListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Image().ListImages(image.NewListImagesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, i := range resp.Payload {
-		if i.ID == nil {
-			continue
+	return c.cached("image", "ImageListCompletion", func() ([]string, error) {
+		resp, err := c.client.Image().ListImages(image.NewListImagesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		names = append(names, *i.ID)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		var names []string
+		for _, i := range resp.Payload {
+			if i.ID == nil {
+				continue
+			}
+			names = append(names, *i.ID)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) ImageNameCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Image().ListImages(image.NewListImagesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, i := range resp.Payload {
-		names = append(names, i.Name)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+	return c.cached("image", "ImageNameCompletion", func() ([]string, error) {
+		resp, err := c.client.Image().ListImages(image.NewListImagesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, i := range resp.Payload {
+			names = append(names, i.Name)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) ImageOSCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Image().ListImages(image.NewListImagesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, i := range resp.Payload {
-		if i.ID == nil {
-			continue
+	return c.cached("image", "ImageOSCompletion", func() ([]string, error) {
+		resp, err := c.client.Image().ListImages(image.NewListImagesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		os, _, err := osAndVersionFromImage(*i.ID)
-		if err == nil {
-			names = append(names, os)
+		var names []string
+		for _, i := range resp.Payload {
+			if i.ID == nil {
+				continue
+			}
+			os, _, err := osAndVersionFromImage(*i.ID)
+			if err == nil {
+				names = append(names, os)
+			}
 		}
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		return names, nil
+	})
 }
 
 func (c *Completion) ImageVersionCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Image().ListImages(image.NewListImagesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, i := range resp.Payload {
-		if i.ID == nil {
-			continue
+	return c.cached("image", "ImageVersionCompletion", func() ([]string, error) {
+		resp, err := c.client.Image().ListImages(image.NewListImagesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		_, version, err := osAndVersionFromImage(*i.ID)
-		if err == nil {
-			names = append(names, version)
+		var names []string
+		for _, i := range resp.Payload {
+			if i.ID == nil {
+				continue
+			}
+			_, version, err := osAndVersionFromImage(*i.ID)
+			if err == nil {
+				names = append(names, version)
+			}
 		}
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		return names, nil
+	})
 }
 
 func osAndVersionFromImage(id string) (os string, version string, err error) {
@@ -7,7 +7,7 @@ import (
 )
 
 func (c *Completion) IpListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.IP().ListIPs(ip.NewListIPsParams(), nil)
+	resp, err := c.client.IP().ListIPs(ip.NewListIPsParams().WithContext(cmd.Context()), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
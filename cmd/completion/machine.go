@@ -8,128 +8,142 @@ import (
 )
 
 func (c *Completion) MachineListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Machine().ListMachines(machine.NewListMachinesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, m := range resp.Payload {
-		name := *m.ID
-		if m.Allocation != nil && *m.Allocation.Hostname != "" {
-			name = name + "\t" + *m.Allocation.Hostname
+	return c.cached("machine", "MachineListCompletion", func() ([]string, error) {
+		resp, err := c.client.Machine().ListMachines(machine.NewListMachinesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		names = append(names, name)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		var names []string
+		for _, m := range resp.Payload {
+			name := *m.ID
+			if m.Allocation != nil && *m.Allocation.Hostname != "" {
+				name = name + "\t" + *m.Allocation.Hostname
+			}
+			names = append(names, name)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) MachineManufacturerCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, m := range resp.Payload {
-		if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
-			continue
+	return c.cached("machine", "MachineManufacturerCompletion", func() ([]string, error) {
+		resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}).WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
+		var names []string
+		for _, m := range resp.Payload {
+			if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
+				continue
+			}
 
-		names = append(names, m.Ipmi.Fru.ProductManufacturer)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+			names = append(names, m.Ipmi.Fru.ProductManufacturer)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) MachineProductPartNumberCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, m := range resp.Payload {
-		if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
-			continue
+	return c.cached("machine", "MachineProductPartNumberCompletion", func() ([]string, error) {
+		resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}).WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
+		var names []string
+		for _, m := range resp.Payload {
+			if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
+				continue
+			}
 
-		names = append(names, m.Ipmi.Fru.ProductPartNumber)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+			names = append(names, m.Ipmi.Fru.ProductPartNumber)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) MachineProductSerialCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, m := range resp.Payload {
-		if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
-			continue
+	return c.cached("machine", "MachineProductSerialCompletion", func() ([]string, error) {
+		resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}).WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
+		var names []string
+		for _, m := range resp.Payload {
+			if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
+				continue
+			}
 
-		names = append(names, m.Ipmi.Fru.ProductSerial)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+			names = append(names, m.Ipmi.Fru.ProductSerial)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) MachineBoardPartNumberCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, m := range resp.Payload {
-		if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
-			continue
+	return c.cached("machine", "MachineBoardPartNumberCompletion", func() ([]string, error) {
+		resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(&models.V1MachineFindRequest{}).WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
+		var names []string
+		for _, m := range resp.Payload {
+			if m == nil || m.Ipmi == nil || m.Ipmi.Fru == nil {
+				continue
+			}
 
-		names = append(names, m.Ipmi.Fru.BoardPartNumber)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+			names = append(names, m.Ipmi.Fru.BoardPartNumber)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) IssueTypeCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, issue := range resp.Payload {
-		if issue.ID == nil {
-			continue
+	return c.cached("machine", "IssueTypeCompletion", func() ([]string, error) {
+		resp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-
-		name := *issue.ID
-		description := pointer.SafeDeref(issue.Description)
-		if description != "" {
-			name = name + "\t" + description
+		var names []string
+		for _, issue := range resp.Payload {
+			if issue.ID == nil {
+				continue
+			}
+
+			name := *issue.ID
+			description := pointer.SafeDeref(issue.Description)
+			if description != "" {
+				name = name + "\t" + description
+			}
+
+			names = append(names, name)
 		}
-
-		names = append(names, name)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		return names, nil
+	})
 }
 
 func (c *Completion) IssueSeverityCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-
-	severities := map[string]bool{}
-	for _, issue := range resp.Payload {
-		if issue.Severity == nil {
-			continue
+	return c.cached("machine", "IssueSeverityCompletion", func() ([]string, error) {
+		resp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
 
-		severities[*issue.Severity] = true
-	}
+		severities := map[string]bool{}
+		for _, issue := range resp.Payload {
+			if issue.Severity == nil {
+				continue
+			}
 
-	var names []string
-	for s := range severities {
-		names = append(names, s)
-	}
+			severities[*issue.Severity] = true
+		}
+
+		var names []string
+		for s := range severities {
+			names = append(names, s)
+		}
 
-	return names, cobra.ShellCompDirectiveNoFileComp
+		return names, nil
+	})
 }
 
 func (c *Completion) MachineRackListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -141,14 +155,16 @@ func (c *Completion) MachineRackListCompletion(cmd *cobra.Command, args []string
 		}
 	}
 
-	resp, err := c.client.Machine().FindMachines(machine.NewFindMachinesParams().WithBody(mfr), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, m := range resp.Payload {
-		names = append(names, m.Rackid)
-	}
+	return c.cached("machine", "MachineRackListCompletion/"+mfr.PartitionID, func() ([]string, error) {
+		resp, err := c.client.Machine().FindMachines(machine.NewFindMachinesParams().WithBody(mfr).WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, m := range resp.Payload {
+			names = append(names, m.Rackid)
+		}
 
-	return names, cobra.ShellCompDirectiveNoFileComp
+		return names, nil
+	})
 }
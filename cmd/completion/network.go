@@ -8,27 +8,31 @@ import (
 )
 
 func (c *Completion) NetworkListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Network().ListNetworks(network.NewListNetworksParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, n := range resp.Payload {
-		names = append(names, *n.ID+"\t"+n.Name)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+	return c.cached("network", "NetworkListCompletion", func() ([]string, error) {
+		resp, err := c.client.Network().ListNetworks(network.NewListNetworksParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, n := range resp.Payload {
+			names = append(names, *n.ID+"\t"+n.Name)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) NetworkDestinationPrefixesCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Network().ListNetworks(network.NewListNetworksParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var prefixes []string
-	for _, n := range resp.Payload {
-		prefixes = append(prefixes, n.Destinationprefixes...)
-	}
-	return prefixes, cobra.ShellCompDirectiveNoFileComp
+	return c.cached("network", "NetworkDestinationPrefixesCompletion", func() ([]string, error) {
+		resp, err := c.client.Network().ListNetworks(network.NewListNetworksParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var prefixes []string
+		for _, n := range resp.Payload {
+			prefixes = append(prefixes, n.Destinationprefixes...)
+		}
+		return prefixes, nil
+	})
 }
 func (c *Completion) NetworkAddressFamilyCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return []string{models.V1NetworkAllocateRequestAddressfamilyIPV4, models.V1NetworkAllocateRequestAddressfamilyIPV6}, cobra.ShellCompDirectiveNoFileComp
@@ -6,7 +6,7 @@ import (
 )
 
 func (c *Completion) PartitionListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Partition().ListPartitions(partition.NewListPartitionsParams(), nil)
+	resp, err := c.client.Partition().ListPartitions(partition.NewListPartitionsParams().WithContext(cmd.Context()), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
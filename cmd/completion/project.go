@@ -6,13 +6,15 @@ import (
 )
 
 func (c *Completion) ProjectListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Project().ListProjects(project.NewListProjectsParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, p := range resp.Payload {
-		names = append(names, p.Meta.ID+"\t"+p.TenantID+"/"+p.Name)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+	return c.cached("project", "ProjectListCompletion", func() ([]string, error) {
+		resp, err := c.client.Project().ListProjects(project.NewListProjectsParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, p := range resp.Payload {
+			names = append(names, p.Meta.ID+"\t"+p.TenantID+"/"+p.Name)
+		}
+		return names, nil
+	})
 }
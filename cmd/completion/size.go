@@ -6,25 +6,29 @@ import (
 )
 
 func (c *Completion) SizeListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Size().ListSizes(size.NewListSizesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, s := range resp.Payload {
-		names = append(names, *s.ID)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+	return c.cached("size", "SizeListCompletion", func() ([]string, error) {
+		resp, err := c.client.Size().ListSizes(size.NewListSizesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, s := range resp.Payload {
+			names = append(names, *s.ID)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) SizeReservationsListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Size().ListSizeReservations(size.NewListSizeReservationsParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, s := range resp.Payload {
-		names = append(names, *s.ID)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+	return c.cached("size", "SizeReservationsListCompletion", func() ([]string, error) {
+		resp, err := c.client.Size().ListSizeReservations(size.NewListSizeReservationsParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, s := range resp.Payload {
+			names = append(names, *s.ID)
+		}
+		return names, nil
+	})
 }
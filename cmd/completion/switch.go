@@ -6,75 +6,85 @@ import (
 )
 
 func (c *Completion) SwitchListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, s := range resp.Payload {
-		if s.ID == nil {
-			continue
+	return c.cached("switch", "SwitchListCompletion", func() ([]string, error) {
+		resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		names = append(names, *s.ID)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		var names []string
+		for _, s := range resp.Payload {
+			if s.ID == nil {
+				continue
+			}
+			names = append(names, *s.ID)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) SwitchNameListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, p := range resp.Payload {
-		names = append(names, p.Name)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+	return c.cached("switch", "SwitchNameListCompletion", func() ([]string, error) {
+		resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
+		}
+		var names []string
+		for _, p := range resp.Payload {
+			names = append(names, p.Name)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) SwitchRackListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, p := range resp.Payload {
-		if p.RackID == nil {
-			continue
+	return c.cached("switch", "SwitchRackListCompletion", func() ([]string, error) {
+		resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		names = append(names, *p.RackID)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		var names []string
+		for _, p := range resp.Payload {
+			if p.RackID == nil {
+				continue
+			}
+			names = append(names, *p.RackID)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) SwitchOSVendorListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, p := range resp.Payload {
-		if p.Os == nil {
-			continue
+	return c.cached("switch", "SwitchOSVendorListCompletion", func() ([]string, error) {
+		resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		names = append(names, p.Os.Vendor)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		var names []string
+		for _, p := range resp.Payload {
+			if p.Os == nil {
+				continue
+			}
+			names = append(names, p.Os.Vendor)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) SwitchOSVersionListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams(), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, p := range resp.Payload {
-		if p.Os == nil {
-			continue
+	return c.cached("switch", "SwitchOSVersionListCompletion", func() ([]string, error) {
+		resp, err := c.client.SwitchOperations().ListSwitches(switch_operations.NewListSwitchesParams().WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-		names = append(names, p.Os.Version)
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		var names []string
+		for _, p := range resp.Payload {
+			if p.Os == nil {
+				continue
+			}
+			names = append(names, p.Os.Version)
+		}
+		return names, nil
+	})
 }
 
 func (c *Completion) SwitchListPorts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -82,15 +92,17 @@ func (c *Completion) SwitchListPorts(cmd *cobra.Command, args []string, toComple
 		// there is no switch selected so we cannot get the list of ports
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
-	resp, err := c.client.SwitchOperations().FindSwitch(switch_operations.NewFindSwitchParams().WithID(args[0]), nil)
-	if err != nil {
-		return nil, cobra.ShellCompDirectiveError
-	}
-	var names []string
-	for _, n := range resp.Payload.Nics {
-		if n != nil {
-			names = append(names, *n.Name)
+	return c.cached("switch", "SwitchListPorts/"+args[0], func() ([]string, error) {
+		resp, err := c.client.SwitchOperations().FindSwitch(switch_operations.NewFindSwitchParams().WithID(args[0]).WithContext(cmd.Context()), nil)
+		if err != nil {
+			return nil, err
 		}
-	}
-	return names, cobra.ShellCompDirectiveNoFileComp
+		var names []string
+		for _, n := range resp.Payload.Nics {
+			if n != nil {
+				names = append(names, *n.Name)
+			}
+		}
+		return names, nil
+	})
 }
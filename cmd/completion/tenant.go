@@ -6,7 +6,7 @@ import (
 )
 
 func (c *Completion) TenantListCompletion(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-	resp, err := c.client.Tenant().ListTenants(tenant.NewListTenantsParams(), nil)
+	resp, err := c.client.Tenant().ListTenants(tenant.NewListTenantsParams().WithContext(cmd.Context()), nil)
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveError
 	}
@@ -2,18 +2,38 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/fatih/color"
+	"github.com/metal-stack/metal-lib/pkg/genericcli"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
 	"github.com/metal-stack/metalctl/pkg/api"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func newContextCmd(c *config) *cobra.Command {
 	contextCmd := &cobra.Command{
-		Use:               "context <name>",
-		Aliases:           []string{"ctx"},
-		Short:             "manage metalctl context",
-		Long:              "context defines the backend to which metalctl talks to. You can switch back and forth with \"-\"",
+		Use:     "context <name>",
+		Aliases: []string{"ctx"},
+		Short:   "manage metalctl context",
+		Long: `context defines the backend to which metalctl talks to. You can switch back and forth with "-".
+Contexts can be managed with the subcommands or by editing the config file.
+
+A context can contain defaults for command line flags, explicitly given flags and environment variables take precedence over them.
+
+Instead of storing secrets in the config file, a credential_helper command can be configured, which prints the credentials as json to stdout:
+{"hmac": "...", "client_secret": "...", "token": "...", "expiration": "2006-01-02T15:04:05Z"}
+All fields are optional. The helper is run with the first request against the metal-api and its credentials are only kept in memory.
+
+The connection to the metal-api can be configured with a certificate authority, a client certificate for mutual tls and a proxy_url,
+certificates and keys are either given as path to a pem file or base64 encoded in the _data fields.
+
+Idempotent requests like finds and gets are retried on transient errors with an exponential backoff, which can be tuned with the retry section of a context.
+
+Shell completions are cached on disk for the completion_cache_ttl of a context, which defaults to 5m, 0s disables the cache.
+
+The columns section selects and orders the columns of tables by table name, the --columns flag takes precedence.`,
 		ValidArgsFunction: c.comp.ContextListCompletion,
 		Example: `
 ~/.metalctl/config.yaml
@@ -29,12 +49,30 @@ contexts:
     url: https://api.metal-stack.dev/metal
     issuer_url: https://dex.metal-stack.dev/dex
     client_id: metal_client
-    client_secret: 123
+    credential_helper:
+      command: pass-metal-credentials
+      args:
+      - dev
+    defaults:
+      project: my-project
+      partition: my-partition
+      output-format: wide
+    certificate_authority_file: /etc/ssl/metal-stack-dev-ca.pem
+    client_certificate: ~/.metalctl/dev-client.pem
+    client_key: ~/.metalctl/dev-client-key.pem
+    proxy_url: http://proxy.corp.example:3128
+    retry:
+      max_retries: 5
+      initial_backoff: 1s
+      max_backoff: 30s
+    completion_cache_ttl: 1m
+    columns:
+      machine: [id, status, hostname, project, rack, started]
 ...
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 1 {
-				return contextSet(args)
+				return c.contextSet(args)
 			}
 			if len(args) == 0 {
 				return c.contextList()
@@ -47,28 +85,122 @@ contexts:
 		Use:   "short",
 		Short: "only show the default context name",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return contextShort()
+			return c.contextShort()
+		},
+	}
+
+	contextAddCmd := &cobra.Command{
+		Use:   "add <name>",
+		Short: "add a new context",
+		Example: `
+metalctl context add prod \
+	--url https://api.metal-stack.io/metal \
+	--issuer-url https://dex.metal-stack.io/dex \
+	--client-id metal_client \
+	--client-secret 456 \
+	--defaults project=my-project,partition=my-partition \
+	--activate
+`,
+		// the flags collide with configuration keys like hmac, they must not configure the client of this command
+		Annotations: map[string]string{unboundFlagsAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.contextAdd(cmd.Flags(), args)
+		},
+	}
+
+	contextRemoveCmd := &cobra.Command{
+		Use:               "remove <name>",
+		Aliases:           []string{"rm", "delete"},
+		Short:             "remove a context",
+		ValidArgsFunction: c.comp.ContextListCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.contextRemove(args)
+		},
+	}
+
+	contextRenameCmd := &cobra.Command{
+		Use:               "rename <old-name> <new-name>",
+		Short:             "rename a context",
+		ValidArgsFunction: c.comp.ContextRenameCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.contextRename(args)
+		},
+	}
+
+	contextSetFieldCmd := &cobra.Command{
+		Use:   "set-field <name> <field> <value>",
+		Short: "set a field of a context, an empty value unsets the field",
+		Long:  "set a field of a context, an empty value unsets the field. Supported fields: " + strings.Join(api.ContextFields, ", "),
+		Example: `
+metalctl context set-field prod issuer_type generic
+metalctl context set-field prod hmac ""
+metalctl context set-field prod defaults.output-format wide
+metalctl context set-field prod retry.max_retries 0
+metalctl context set-field prod completion_cache_ttl 0s
+metalctl context set-field prod columns.machine id,hostname,rack
+`,
+		ValidArgsFunction: c.comp.ContextFieldCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.contextSetField(args)
 		},
 	}
+
+	contextDescribeCmd := &cobra.Command{
+		Use:               "describe [<name>]",
+		Aliases:           []string{"get"},
+		Short:             "describes a context, if no name is given the current context is described",
+		ValidArgsFunction: c.comp.ContextListCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.contextDescribe(args)
+		},
+	}
+
+	contextAddCmd.Flags().String("url", "", "the url of the metal-api. [required]")
+	contextAddCmd.Flags().String("issuer-url", "", "the url of the oidc issuer. [optional]")
+	contextAddCmd.Flags().String("issuer-type", "", "the type of the oidc issuer: "+strings.Join(api.IssuerTypes, "|")+" [optional]")
+	contextAddCmd.Flags().String("custom-scopes", "", "comma-separated custom scopes to request from the oidc issuer. [optional]")
+	contextAddCmd.Flags().String("client-id", "", "the oidc client id. [optional]")
+	contextAddCmd.Flags().String("client-secret", "", "the oidc client secret. [optional]")
+	contextAddCmd.Flags().String("hmac", "", "the hmac key for authenticating against the metal-api. [optional]")
+	contextAddCmd.Flags().String("hmac-auth-type", "", "the hmac auth type: "+strings.Join(api.HMACAuthTypes, "|")+" [optional]")
+	contextAddCmd.Flags().String("credential-helper", "", "command line of a credential helper, which prints the hmac, client_secret or token of the context as json to stdout. [optional]")
+	contextAddCmd.Flags().String("certificate-authority-data", "", "base64 encoded ca certificate of the metal-api. [optional]")
+	contextAddCmd.Flags().String("certificate-authority-file", "", "path to the pem encoded ca certificate of the metal-api. [optional]")
+	contextAddCmd.Flags().String("client-certificate", "", "path to the pem encoded client certificate for mutual tls. [optional]")
+	contextAddCmd.Flags().String("client-key", "", "path to the pem encoded key of the client certificate for mutual tls. [optional]")
+	contextAddCmd.Flags().Bool("insecure-skip-verify", false, "disables the verification of the metal-api server certificate, never use this in production. [optional]")
+	contextAddCmd.Flags().String("tls-server-name", "", "the server name used for verifying the metal-api server certificate. [optional]")
+	contextAddCmd.Flags().String("proxy-url", "", "the url of a http(s) or socks5 proxy for connecting to the metal-api, proxy environment variables are used if not set. [optional]")
+	contextAddCmd.Flags().StringToString("defaults", nil, "default values for command line flags when using this context, e.g. --defaults project=my-project,partition=my-partition [optional]")
+	contextAddCmd.Flags().Bool("activate", false, "switch to the added context.")
+	genericcli.Must(contextAddCmd.MarkFlagRequired("url"))
+	genericcli.Must(contextAddCmd.RegisterFlagCompletionFunc("issuer-type", cobra.FixedCompletions(api.IssuerTypes, cobra.ShellCompDirectiveNoFileComp)))
+	genericcli.Must(contextAddCmd.RegisterFlagCompletionFunc("hmac-auth-type", cobra.FixedCompletions(api.HMACAuthTypes, cobra.ShellCompDirectiveNoFileComp)))
+
 	contextCmd.AddCommand(contextShortCmd)
+	contextCmd.AddCommand(contextAddCmd)
+	contextCmd.AddCommand(contextRemoveCmd)
+	contextCmd.AddCommand(contextRenameCmd)
+	contextCmd.AddCommand(contextSetFieldCmd)
+	contextCmd.AddCommand(contextDescribeCmd)
 	return contextCmd
 }
 
-func contextShort() error {
+func (c *config) contextShort() error {
 	ctxs, err := api.GetContexts()
 	if err != nil {
 		return err
 	}
-	fmt.Println(ctxs.CurrentContext)
-	return nil
+	_, err = fmt.Fprintln(c.out, ctxs.ActiveContextName())
+	return err
 }
 
-func contextSet(args []string) error {
+func (c *config) contextSet(args []string) error {
 	if len(args) < 1 {
 		return fmt.Errorf("no context name given")
 	}
 	if args[0] == "-" {
-		return previous()
+		return c.previous()
 	}
 	ctxs, err := api.GetContexts()
 	if err != nil {
@@ -80,15 +212,22 @@ func contextSet(args []string) error {
 		return fmt.Errorf("context %s not found", nextCtx)
 	}
 	if nextCtx == ctxs.CurrentContext {
-		fmt.Printf("%s context \"%s\" already active\n", color.GreenString("✔"), color.GreenString(ctxs.CurrentContext))
+		_, _ = fmt.Fprintf(c.out, "%s context \"%s\" already active\n", color.GreenString("✔"), color.GreenString(ctxs.CurrentContext))
 		return nil
 	}
-	ctxs.PreviousContext = ctxs.CurrentContext
-	ctxs.CurrentContext = nextCtx
-	return api.WriteContexts(ctxs)
+	err = api.UpdateContexts(func(userCtxs *api.Contexts) error {
+		userCtxs.PreviousContext = ctxs.CurrentContext
+		userCtxs.CurrentContext = nextCtx
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.out, "%s switched context to \"%s\"\n", color.GreenString("✔"), color.GreenString(nextCtx))
+	return nil
 }
 
-func previous() error {
+func (c *config) previous() error {
 	ctxs, err := api.GetContexts()
 	if err != nil {
 		return err
@@ -98,9 +237,16 @@ func previous() error {
 		return fmt.Errorf("no previous context found")
 	}
 	curr := ctxs.CurrentContext
-	ctxs.PreviousContext = curr
-	ctxs.CurrentContext = prev
-	return api.WriteContexts(ctxs)
+	err = api.UpdateContexts(func(userCtxs *api.Contexts) error {
+		userCtxs.PreviousContext = curr
+		userCtxs.CurrentContext = prev
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(c.out, "%s switched context to \"%s\"\n", color.GreenString("✔"), color.GreenString(prev))
+	return nil
 }
 
 func (c *config) contextList() error {
@@ -108,5 +254,220 @@ func (c *config) contextList() error {
 	if err != nil {
 		return err
 	}
+	// only for marking the active context, the config file is not written
+	ctxs.CurrentContext = ctxs.ActiveContextName()
 	return c.listPrinter.Print(ctxs)
 }
+
+func (c *config) contextAdd(flags *pflag.FlagSet, args []string) error {
+	name, err := genericcli.GetExactlyOneArg(args)
+	if err != nil {
+		return err
+	}
+
+	getString := func(name string) string {
+		value, err := flags.GetString(name)
+		genericcli.Must(err)
+		return value
+	}
+	insecureSkipVerify, err := flags.GetBool("insecure-skip-verify")
+	if err != nil {
+		return err
+	}
+	defaults, err := flags.GetStringToString("defaults")
+	if err != nil {
+		return err
+	}
+	activate, err := flags.GetBool("activate")
+	if err != nil {
+		return err
+	}
+
+	ctx := api.Context{
+		ApiURL:                   getString("url"),
+		CertificateAuthorityData: getString("certificate-authority-data"),
+		CertificateAuthorityFile: getString("certificate-authority-file"),
+		ClientCertificate:        getString("client-certificate"),
+		ClientKey:                getString("client-key"),
+		InsecureSkipVerify:       insecureSkipVerify,
+		TLSServerName:            getString("tls-server-name"),
+		ProxyURL:                 getString("proxy-url"),
+		IssuerURL:                getString("issuer-url"),
+		IssuerType:               getString("issuer-type"),
+		CustomScopes:             getString("custom-scopes"),
+		ClientID:                 getString("client-id"),
+		ClientSecret:             getString("client-secret"),
+		HMAC:                     pointer.PointerOrNil(getString("hmac")),
+		HMACAuthType:             getString("hmac-auth-type"),
+		Defaults:                 defaults,
+	}
+	err = ctx.SetField("credential_helper", getString("credential-helper"))
+	if err != nil {
+		return err
+	}
+	err = ctx.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = api.UpdateContexts(func(ctxs *api.Contexts) error {
+		if _, ok := ctxs.Contexts[name]; ok {
+			return fmt.Errorf("context %s already exists", name)
+		}
+
+		ctxs.Contexts[name] = ctx
+		if activate || ctxs.CurrentContext == "" {
+			ctxs.PreviousContext = ctxs.CurrentContext
+			ctxs.CurrentContext = name
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.out, "%s added context \"%s\"\n", color.GreenString("✔"), color.GreenString(name))
+	return nil
+}
+
+func (c *config) contextRemove(args []string) error {
+	name, err := genericcli.GetExactlyOneArg(args)
+	if err != nil {
+		return err
+	}
+
+	err = api.UpdateContexts(func(ctxs *api.Contexts) error {
+		if _, ok := ctxs.Contexts[name]; !ok {
+			return errContextNotInUserConfig(name)
+		}
+
+		delete(ctxs.Contexts, name)
+		if ctxs.CurrentContext == name {
+			ctxs.CurrentContext = ""
+		}
+		if ctxs.PreviousContext == name {
+			ctxs.PreviousContext = ""
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the cached token must not be used by a new context with the same name
+	_, err = api.RemoveClientCredentialsToken(name)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.out, "%s removed context \"%s\"\n", color.GreenString("✔"), color.GreenString(name))
+	return nil
+}
+
+func (c *config) contextRename(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("expecting exactly two arguments: <old-name> <new-name>")
+	}
+	oldName, newName := args[0], args[1]
+
+	err := api.UpdateContexts(func(ctxs *api.Contexts) error {
+		ctx, ok := ctxs.Contexts[oldName]
+		if !ok {
+			return errContextNotInUserConfig(oldName)
+		}
+		if _, ok := ctxs.Contexts[newName]; ok {
+			return fmt.Errorf("context %s already exists", newName)
+		}
+
+		delete(ctxs.Contexts, oldName)
+		ctxs.Contexts[newName] = ctx
+		if ctxs.CurrentContext == oldName {
+			ctxs.CurrentContext = newName
+		}
+		if ctxs.PreviousContext == oldName {
+			ctxs.PreviousContext = newName
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	err = api.RenameClientCredentialsToken(oldName, newName)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.out, "%s renamed context \"%s\" to \"%s\"\n", color.GreenString("✔"), oldName, color.GreenString(newName))
+	return nil
+}
+
+func (c *config) contextSetField(args []string) error {
+	if len(args) != 3 {
+		return fmt.Errorf("expecting exactly three arguments: <name> <field> <value>")
+	}
+	name, field, value := args[0], args[1], args[2]
+
+	err := api.UpdateContexts(func(ctxs *api.Contexts) error {
+		ctx, ok := ctxs.Contexts[name]
+		if !ok {
+			return errContextNotInUserConfig(name)
+		}
+
+		err := ctx.SetField(field, value)
+		if err != nil {
+			return err
+		}
+		err = ctx.Validate()
+		if err != nil {
+			return err
+		}
+
+		ctxs.Contexts[name] = ctx
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.out, "%s set field %s of context \"%s\"\n", color.GreenString("✔"), field, color.GreenString(name))
+	return nil
+}
+
+func (c *config) contextDescribe(args []string) error {
+	ctxs, err := api.GetContexts()
+	if err != nil {
+		return err
+	}
+
+	name := ctxs.ActiveContextName()
+	if len(args) > 0 {
+		name, err = genericcli.GetExactlyOneArg(args)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, ok := ctxs.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %s not found", name)
+	}
+
+	return c.describePrinter.Print(&api.NamedContext{
+		Name:    name,
+		Current: name == ctxs.ActiveContextName(),
+		Context: ctx.Redacted(),
+	})
+}
+
+func errContextNotInUserConfig(name string) error {
+	path, err := api.UserConfigFile()
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("context %s not found in %s, contexts from other config files can not be modified", name, path)
+}
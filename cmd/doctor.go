@@ -0,0 +1,306 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/metal-stack/metal-go/api/client/health"
+	"github.com/metal-stack/metal-go/api/client/version"
+	"github.com/metal-stack/metal-lib/jwt/sec"
+	"github.com/metal-stack/metal-lib/pkg/genericcli"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+	"github.com/metal-stack/metalctl/cmd/tableprinters"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type doctorCmd struct {
+	*config
+}
+
+func newDoctorCmd(c *config) *cobra.Command {
+	w := &doctorCmd{
+		config: c,
+	}
+
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "diagnose the metalctl environment",
+		Long: `runs a series of checks against the config, the current context, the authentication and the metal-api.
+Every check results in pass, warn or fail. The command exits with a non-zero exit code in case a check failed.`,
+		// the regular initialization is done as part of the checks such that errors can be reported
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+			c.ctx = cmd.Context()
+			viper.SetFs(c.fs)
+			genericcli.Must(viper.BindPFlags(cmd.Flags()))
+			genericcli.Must(viper.BindPFlags(cmd.PersistentFlags()))
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return w.doctor(cmd)
+		},
+	}
+
+	return doctorCmd
+}
+
+func (c *doctorCmd) doctor(cmd *cobra.Command) error {
+	var checks []*tableprinters.DoctorCheck
+
+	check := func(name string, fn func() (tableprinters.DoctorCheckStatus, string)) bool {
+		status, message := fn()
+		checks = append(checks, &tableprinters.DoctorCheck{
+			Name:    name,
+			Status:  status,
+			Message: message,
+		})
+		return status != tableprinters.DoctorCheckStatusFail
+	}
+
+	initialized := check("config file", c.checkConfigFile) &&
+		check("context", c.checkContext) &&
+		check("certificate authority", c.checkCertificateAuthority) &&
+		check("client", func() (tableprinters.DoctorCheckStatus, string) {
+			return c.checkClient(cmd)
+		})
+
+	if initialized {
+		check("api reachable", c.checkAPIReachable)
+		check("authentication", c.checkAuthentication)
+		check("client version", c.checkClientVersion)
+	}
+
+	if c.listPrinter == nil {
+		c.listPrinter = newPrinterFromCLI(c.out, nil)
+	}
+
+	err := c.listPrinter.Print(checks)
+	if err != nil {
+		return err
+	}
+
+	failed := slices.IndexFunc(checks, func(c *tableprinters.DoctorCheck) bool {
+		return c.Status == tableprinters.DoctorCheckStatusFail
+	})
+	if failed >= 0 {
+		return fmt.Errorf("check %q failed", checks[failed].Name)
+	}
+
+	return nil
+}
+
+func (c *doctorCmd) checkConfigFile() (tableprinters.DoctorCheckStatus, string) {
+	err := readConfigFile()
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, err.Error()
+	}
+
+	var found []string
+	for _, layer := range api.ConfigLayers() {
+		if _, err := os.Stat(layer); err == nil {
+			found = append(found, layer)
+		}
+	}
+
+	if len(found) == 0 {
+		return tableprinters.DoctorCheckStatusWarn, "no config file found, searched in: " + strings.Join(api.ConfigLayers(), ", ")
+	}
+
+	return tableprinters.DoctorCheckStatusPass, "using " + strings.Join(found, ", ")
+}
+
+func (c *doctorCmd) checkContext() (tableprinters.DoctorCheckStatus, string) {
+	ctxs, err := api.GetContexts()
+	if err != nil {
+		if viper.GetString("api-url") != "" {
+			return tableprinters.DoctorCheckStatusWarn, "no contexts configured, using api-url " + viper.GetString("api-url")
+		}
+		return tableprinters.DoctorCheckStatusFail, err.Error()
+	}
+
+	name := ctxs.ActiveContextName()
+	if name == "" {
+		return tableprinters.DoctorCheckStatusFail, "no context selected, switch to a context with metalctl context <name>"
+	}
+
+	ctx, ok := ctxs.Contexts[name]
+	if !ok {
+		return tableprinters.DoctorCheckStatusFail, fmt.Sprintf("context %s not found", name)
+	}
+
+	err = ctx.Validate()
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, fmt.Sprintf("context %s is invalid: %s", name, err)
+	}
+
+	return tableprinters.DoctorCheckStatusPass, fmt.Sprintf("using context %s", name)
+}
+
+func (c *doctorCmd) checkCertificateAuthority() (tableprinters.DoctorCheckStatus, string) {
+	ctx := api.MustDefaultContext()
+	if caData := viper.GetString("certificate-authority-data"); caData != "" {
+		ctx.CertificateAuthorityData = caData
+		ctx.CertificateAuthorityFile = ""
+	}
+
+	_, err := createTLSClientConfig(ctx)
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, err.Error()
+	}
+
+	if ctx.InsecureSkipVerify {
+		return tableprinters.DoctorCheckStatusWarn, "verification of the server certificate is disabled by insecure_skip_verify"
+	}
+	if ctx.CertificateAuthorityData == "" && ctx.CertificateAuthorityFile == "" {
+		return tableprinters.DoctorCheckStatusPass, "no certificate authority configured, using system certificates"
+	}
+
+	return tableprinters.DoctorCheckStatusPass, "certificate authority is valid"
+}
+
+func (c *doctorCmd) checkClient(cmd *cobra.Command) (tableprinters.DoctorCheckStatus, string) {
+	err := applyContextDefaults(cmd)
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, err.Error()
+	}
+
+	err = initConfigWithViperCtx(c.config)
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, err.Error()
+	}
+
+	return tableprinters.DoctorCheckStatusPass, "client initialized"
+}
+
+func (c *doctorCmd) checkAPIReachable() (tableprinters.DoctorCheckStatus, string) {
+	resp, err := c.client.Health().Health(health.NewHealthParams().WithContext(c.ctx), nil)
+	if err != nil {
+		var r *health.HealthInternalServerError
+		if errors.As(err, &r) {
+			return tableprinters.DoctorCheckStatusWarn, fmt.Sprintf("api is reachable but unhealthy: %s", pointer.SafeDeref(pointer.SafeDeref(r.Payload).Message))
+		}
+		return tableprinters.DoctorCheckStatusFail, fmt.Sprintf("api is not reachable: %s", err)
+	}
+
+	return tableprinters.DoctorCheckStatusPass, fmt.Sprintf("api is reachable, status %s", pointer.SafeDeref(resp.Payload.Status))
+}
+
+func (c *doctorCmd) checkAuthentication() (tableprinters.DoctorCheckStatus, string) {
+	ctx := api.MustDefaultContext()
+
+	hmacKey := viper.GetString("hmac")
+	if hmacKey == "" && ctx.HMAC != nil {
+		hmacKey = *ctx.HMAC
+	}
+	if hmacKey != "" {
+		return checkHMACAuthType(ctx, "using hmac authentication")
+	}
+
+	if viper.GetString("api-token") != "" {
+		return tableprinters.DoctorCheckStatusPass, "using api token"
+	}
+
+	clientSecret := ctx.ClientSecret
+	if ctx.CredentialHelper != nil {
+		creds, err := ctx.CredentialHelper.Credentials()
+		if err != nil {
+			return tableprinters.DoctorCheckStatusFail, err.Error()
+		}
+
+		switch {
+		case creds.HMAC != "":
+			return checkHMACAuthType(ctx, fmt.Sprintf("using hmac authentication from credential helper %s", ctx.CredentialHelper.Command))
+		case creds.Token != "":
+			return checkTokenExpiry(creds.Token, fmt.Sprintf("token from credential helper %s", ctx.CredentialHelper.Command))
+		}
+
+		if clientSecret == "" {
+			clientSecret = creds.ClientSecret
+		}
+	}
+
+	contextName := ""
+	if ctxs, err := api.GetContexts(); err == nil {
+		contextName = ctxs.ActiveContextName()
+	}
+	cached, err := api.CachedClientCredentialsToken(contextName, api.ClientCredentialsConfig{
+		IssuerURL: ctx.IssuerURL,
+		ClientID:  ctx.ClientID,
+		ApiURL:    contextAPIURL(ctx),
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return tableprinters.DoctorCheckStatusFail, err.Error()
+	}
+	if err == nil {
+		switch {
+		case !cached.Expired():
+			return tableprinters.DoctorCheckStatusPass, fmt.Sprintf("using client credentials token, which is valid until %s", cached.Expiry.Format(time.RFC3339))
+		case clientSecret != "":
+			return tableprinters.DoctorCheckStatusPass, "client credentials token expired, it is re-acquired with the client secret of the context"
+		default:
+			return tableprinters.DoctorCheckStatusFail, "client credentials token expired and the context has no client secret, please run metalctl login"
+		}
+	}
+
+	authContext, err := getAuthContext(viper.GetString("kubeconfig"))
+	if err != nil {
+		return tableprinters.DoctorCheckStatusWarn, fmt.Sprintf("no token found, please run metalctl login: %s", err)
+	}
+
+	return checkTokenExpiry(authContext.IDToken, "token in kubeconfig")
+}
+
+func checkHMACAuthType(ctx api.Context, msg string) (tableprinters.DoctorCheckStatus, string) {
+	hmacAuthType := viper.GetString("hmac-auth-type")
+	if hmacAuthType == "" {
+		hmacAuthType = ctx.HMACAuthType
+	}
+	if !slices.Contains(api.HMACAuthTypes, hmacAuthType) {
+		return tableprinters.DoctorCheckStatusFail, fmt.Sprintf("hmac auth type %q is invalid, must be one of: %s", hmacAuthType, strings.Join(api.HMACAuthTypes, "|"))
+	}
+	return tableprinters.DoctorCheckStatusPass, fmt.Sprintf("%s with auth type %s", msg, hmacAuthType)
+}
+
+func checkTokenExpiry(token, source string) (tableprinters.DoctorCheckStatus, string) {
+	_, claims, err := sec.ParseTokenUnvalidatedUnfiltered(token)
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, fmt.Sprintf("%s is not parsable: %s", source, err)
+	}
+
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	remaining := time.Until(expiresAt)
+	switch {
+	case remaining <= 0:
+		return tableprinters.DoctorCheckStatusFail, fmt.Sprintf("%s expired at %s, please run metalctl login", source, expiresAt.Format(time.RFC3339))
+	case remaining < viper.GetDuration("token-expiry-warning"):
+		return tableprinters.DoctorCheckStatusWarn, fmt.Sprintf("%s expires soon at %s", source, expiresAt.Format(time.RFC3339))
+	default:
+		return tableprinters.DoctorCheckStatusPass, fmt.Sprintf("%s is valid until %s", source, expiresAt.Format(time.RFC3339))
+	}
+}
+
+func (c *doctorCmd) checkClientVersion() (tableprinters.DoctorCheckStatus, string) {
+	resp, err := c.client.Version().Info(version.NewInfoParams().WithContext(c.ctx), nil)
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, fmt.Sprintf("unable to get server version: %s", err)
+	}
+
+	if resp.Payload == nil || resp.Payload.MinClientVersion == nil {
+		return tableprinters.DoctorCheckStatusPass, "metal-api does not require a minimum client version"
+	}
+
+	warning, err := checkMinClientVersion(*resp.Payload.MinClientVersion)
+	if err != nil {
+		return tableprinters.DoctorCheckStatusFail, err.Error()
+	}
+	if warning != "" {
+		return tableprinters.DoctorCheckStatusWarn, warning
+	}
+
+	return tableprinters.DoctorCheckStatusPass, fmt.Sprintf("client version satisfies minimum version %s", *resp.Payload.MinClientVersion)
+}
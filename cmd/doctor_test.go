@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metal-stack/metal-go/api/client/health"
+	"github.com/metal-stack/metal-go/api/client/version"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-go/test/client"
+	"github.com/metal-stack/metal-lib/pkg/healthstatus"
+	"github.com/metal-stack/metal-lib/pkg/testcommon"
+	"github.com/metal-stack/metalctl/cmd/tableprinters"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/spf13/afero"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DoctorCmd(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+	cfg := []byte(`current: test
+contexts:
+  test:
+    url: https://metal.test
+    hmac: secret
+    hmac_auth_type: Metal-View
+`)
+	require.NoError(t, os.WriteFile(cfgFile, cfg, 0600))
+
+	tests := []*test[[]*tableprinters.DoctorCheck]{
+		{
+			name: "doctor",
+			cmd: func(want []*tableprinters.DoctorCheck) []string {
+				return []string{"doctor", "--config", cfgFile}
+			},
+			fsMocks: func(fs afero.Fs, want []*tableprinters.DoctorCheck) {
+				require.NoError(t, afero.WriteFile(fs, cfgFile, cfg, 0600))
+			},
+			mocks: &client.MetalMockFns{
+				Health: func(mock *mock.Mock) {
+					mock.On("Health", testcommon.MatchIgnoreContext(t, health.NewHealthParams()), nil).Return(&health.HealthOK{
+						Payload: &models.RestHealthResponse{
+							Status:  new(string(healthstatus.HealthStatusHealthy)),
+							Message: new("ok"),
+						},
+					}, nil)
+				},
+				Version: func(mock *mock.Mock) {
+					mock.On("Info", testcommon.MatchIgnoreContext(t, version.NewInfoParams()), nil).Return(&version.InfoOK{
+						Payload: &models.RestVersion{
+							Version: new("server v1.0.0"),
+						},
+					}, nil)
+				},
+			},
+			want: []*tableprinters.DoctorCheck{
+				{Name: "config file", Status: tableprinters.DoctorCheckStatusPass, Message: "using " + cfgFile},
+				{Name: "context", Status: tableprinters.DoctorCheckStatusPass, Message: "using context test"},
+				{Name: "certificate authority", Status: tableprinters.DoctorCheckStatusPass, Message: "no certificate authority configured, using system certificates"},
+				{Name: "client", Status: tableprinters.DoctorCheckStatusPass, Message: "client initialized"},
+				{Name: "api reachable", Status: tableprinters.DoctorCheckStatusPass, Message: "api is reachable, status healthy"},
+				{Name: "authentication", Status: tableprinters.DoctorCheckStatusPass, Message: "using hmac authentication with auth type Metal-View"},
+				{Name: "client version", Status: tableprinters.DoctorCheckStatusPass, Message: "metal-api does not require a minimum client version"},
+			},
+			wantTable: new(`
+CHECK                  STATUS  MESSAGE
+config file            pass    using ` + cfgFile + `
+context                pass    using context test
+certificate authority  pass    no certificate authority configured, using system certificates
+client                 pass    client initialized
+api reachable          pass    api is reachable, status healthy
+authentication         pass    using hmac authentication with auth type Metal-View
+client version         pass    metal-api does not require a minimum client version
+`),
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
+
+func Test_doctorCmd_checkAuthentication(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	helper := filepath.Join(dir, "helper.sh")
+	require.NoError(t, os.WriteFile(helper, []byte(`#!/bin/sh
+echo "$1"
+`), 0700))
+
+	validUntil := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		context     string
+		cachedToken *api.ClientCredentialsToken
+		wantStatus  tableprinters.DoctorCheckStatus
+		wantMessage string
+	}{
+		{
+			name: "credential helper with hmac",
+			context: `
+    hmac_auth_type: Metal-View
+    credential_helper:
+      command: ` + helper + `
+      args: ['{"hmac": "secret"}']`,
+			wantStatus:  tableprinters.DoctorCheckStatusPass,
+			wantMessage: "using hmac authentication from credential helper " + helper + " with auth type Metal-View",
+		},
+		{
+			name: "failing credential helper",
+			context: `
+    credential_helper:
+      command: "false"`,
+			wantStatus:  tableprinters.DoctorCheckStatusFail,
+			wantMessage: `credential helper "false" failed: exit status 1`,
+		},
+		{
+			name: "client credentials with secret from credential helper",
+			context: `
+    credential_helper:
+      command: ` + helper + `
+      args: ['{"client_secret": "secret"}']`,
+			cachedToken: &api.ClientCredentialsToken{Token: "token", Expiry: validUntil, ApiURL: "https://metal.test"},
+			wantStatus:  tableprinters.DoctorCheckStatusPass,
+			wantMessage: "using client credentials token, which is valid until " + validUntil.Format(time.RFC3339),
+		},
+		{
+			name:        "expired client credentials without secret",
+			cachedToken: &api.ClientCredentialsToken{Token: "token", Expiry: time.Now().Add(-time.Hour), ApiURL: "https://metal.test"},
+			wantStatus:  tableprinters.DoctorCheckStatusFail,
+			wantMessage: "client credentials token expired and the context has no client secret, please run metalctl login",
+		},
+		{
+			name:        "client credentials token of another api",
+			cachedToken: &api.ClientCredentialsToken{Token: "token", Expiry: validUntil, ApiURL: "https://metal.other"},
+			wantStatus:  tableprinters.DoctorCheckStatusFail,
+			wantMessage: `token cache ` + filepath.Join(dir, "cache", "metalctl", "tokens", "test.json") + ` was acquired for issuer "", client "" and api "https://metal.other", which does not match the context, please run metalctl login`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+
+			cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+			require.NoError(t, os.WriteFile(cfgFile, []byte(`current: test
+contexts:
+  test:
+    url: https://metal.test`+tt.context+"\n"), 0600))
+			viper.Set("config", cfgFile)
+			viper.Set("kubeconfig", filepath.Join(t.TempDir(), "kubeconfig"))
+
+			tokenCache := filepath.Join(dir, "cache", "metalctl", "tokens", "test.json")
+			require.NoError(t, os.RemoveAll(tokenCache))
+			if tt.cachedToken != nil {
+				require.NoError(t, os.MkdirAll(filepath.Dir(tokenCache), 0700))
+				require.NoError(t, os.WriteFile(tokenCache, mustMarshal(t, tt.cachedToken), 0600))
+			}
+
+			status, msg := (&doctorCmd{}).checkAuthentication()
+
+			require.Equal(t, tt.wantStatus, status)
+			require.Equal(t, tt.wantMessage, msg)
+		})
+	}
+}
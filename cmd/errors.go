@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/uuid"
+	"github.com/metal-stack/metal-lib/httperrors"
+	"github.com/spf13/cobra"
+)
+
+// exit codes of metalctl, which allow scripts to distinguish the kind of failure
+const (
+	exitCodeError        = 1
+	exitCodeUsage        = 2
+	exitCodeUnauthorized = 3
+	exitCodeForbidden    = 4
+	exitCodeNotFound     = 5
+	exitCodeConflict     = 6
+	exitCodeBadRequest   = 7
+	exitCodeServerError  = 8
+	exitCodeNetwork      = 9
+	exitCodeTimeout      = 10
+	exitCodeInterrupted  = 130
+)
+
+const exitCodesHelp = `Exit codes:
+  0    success
+  1    general error
+  2    invalid usage, e.g. unknown commands or flags, invalid arguments or missing required flags
+  3    unauthorized, the token is missing, invalid or expired
+  4    forbidden, missing permissions for the operation
+  5    not found
+  6    conflict, e.g. the entity already exists
+  7    bad request, the metal-api rejected the request as invalid
+  8    server error of the metal-api
+  9    network error, the metal-api is not reachable
+  10   timeout
+  130  interrupted`
+
+// cliError describes why a command failed, it is printed to stderr as json with --output-format json
+type cliError struct {
+	Code       int    `json:"code"`
+	Message    string `json:"message"`
+	RequestID  string `json:"request_id,omitempty"`
+	HTTPStatus int    `json:"http_status,omitempty"`
+}
+
+// usageError marks errors caused by an invalid invocation of metalctl
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// markUsageErrors marks the errors of the argument validation of all commands as usage errors
+func markUsageErrors(cmd *cobra.Command) {
+	if validateArgs := cmd.Args; validateArgs != nil {
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			err := validateArgs(cmd, args)
+			if err != nil {
+				return &usageError{err: err}
+			}
+			return nil
+		}
+	}
+
+	for _, child := range cmd.Commands() {
+		markUsageErrors(child)
+	}
+}
+
+// validateFlags checks the required flags and flag groups, which cobra otherwise does with errors that are not marked as usage errors
+func validateFlags(cmd *cobra.Command) error {
+	err := cmd.ValidateRequiredFlags()
+	if err != nil {
+		return &usageError{err: err}
+	}
+	err = cmd.ValidateFlagGroups()
+	if err != nil {
+		return &usageError{err: err}
+	}
+	return nil
+}
+
+// isUnknownCommand returns true for the error of cobra for an unknown sub command, which is not a distinct error type
+func isUnknownCommand(err error) bool {
+	return strings.HasPrefix(err.Error(), "unknown command ")
+}
+
+// toCLIError maps the error to an exit code, errors returned by the metal-api are mapped by their http status
+func toCLIError(err error, requestID string) *cliError {
+	result := &cliError{
+		Code:    exitCodeError,
+		Message: err.Error(),
+	}
+
+	var (
+		apiErr     interface{ Code() int }
+		runtimeErr *runtime.APIError
+		payloadErr interface {
+			GetPayload() *httperrors.HTTPErrorResponse
+		}
+		usageErr *usageError
+		netErr   net.Error
+	)
+
+	switch {
+	case errors.As(err, &usageErr), isUnknownCommand(err):
+		result.Code = exitCodeUsage
+		return result
+	case errors.Is(err, context.Canceled):
+		result.Code = exitCodeInterrupted
+		return result
+	case errors.Is(err, context.DeadlineExceeded):
+		result.Code = exitCodeTimeout
+	case errors.As(err, &apiErr):
+		result.HTTPStatus = apiErr.Code()
+	case errors.As(err, &runtimeErr):
+		result.HTTPStatus = runtimeErr.Code
+	case errors.As(err, &netErr):
+		result.Code = exitCodeNetwork
+		if netErr.Timeout() {
+			result.Code = exitCodeTimeout
+		}
+	default:
+		return result
+	}
+
+	result.RequestID = requestID
+
+	if errors.As(err, &payloadErr) && payloadErr.GetPayload() != nil && payloadErr.GetPayload().Message != "" {
+		result.Message = payloadErr.GetPayload().Message
+	}
+
+	switch status := result.HTTPStatus; {
+	case status == 0:
+	case status == http.StatusUnauthorized:
+		result.Code = exitCodeUnauthorized
+	case status == http.StatusForbidden:
+		result.Code = exitCodeForbidden
+	case status == http.StatusNotFound:
+		result.Code = exitCodeNotFound
+	case status == http.StatusConflict:
+		result.Code = exitCodeConflict
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		result.Code = exitCodeTimeout
+	case status >= 500:
+		result.Code = exitCodeServerError
+	case status >= 400:
+		result.Code = exitCodeBadRequest
+	}
+
+	return result
+}
+
+// requestIDTransport sets a request id on every request, which is logged by the metal-api
+// and allows finding the server side logs of a failed request
+type requestIDTransport struct {
+	next http.RoundTripper
+
+	mu   sync.Mutex
+	last string
+}
+
+func (t *requestIDTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	requestID := r.Header.Get("X-Request-Id")
+	if requestID == "" {
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, err
+		}
+		requestID = id.String()
+
+		r = r.Clone(r.Context())
+		r.Header.Set("X-Request-Id", requestID)
+	}
+
+	t.mu.Lock()
+	t.last = requestID
+	t.mu.Unlock()
+
+	return t.next.RoundTrip(r)
+}
+
+// lastRequestID returns the id of the most recent request, which is the failing one in case a command failed
+func (t *requestIDTransport) lastRequestID() string {
+	if t == nil {
+		return ""
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
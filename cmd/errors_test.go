@@ -0,0 +1,188 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-openapi/runtime"
+	"github.com/google/go-cmp/cmp"
+	"github.com/metal-stack/metal-go/api/client/health"
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/client/tenant"
+	"github.com/metal-stack/metal-lib/httperrors"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_toCLIError(t *testing.T) {
+	notFound := machine.NewFindMachineDefault(http.StatusNotFound)
+	notFound.Payload = &httperrors.HTTPErrorResponse{StatusCode: http.StatusNotFound, Message: "machine 1 not found"}
+
+	unauthorized := machine.NewFindMachineDefault(http.StatusUnauthorized)
+
+	conflict := tenant.NewCreateTenantConflict()
+	conflict.Payload = &httperrors.HTTPErrorResponse{StatusCode: http.StatusConflict, Message: "tenant already exists"}
+
+	tests := []struct {
+		name string
+		err  error
+		want *cliError
+	}{
+		{
+			name: "general error",
+			err:  errors.New("something went wrong"),
+			want: &cliError{Code: exitCodeError, Message: "something went wrong"},
+		},
+		{
+			name: "usage error",
+			err:  &usageError{err: errors.New("unknown flag: --foo")},
+			want: &cliError{Code: exitCodeUsage, Message: "unknown flag: --foo"},
+		},
+		{
+			name: "unknown command",
+			err:  errors.New(`unknown command "foo" for "metalctl"`),
+			want: &cliError{Code: exitCodeUsage, Message: `unknown command "foo" for "metalctl"`},
+		},
+		{
+			name: "not found",
+			err:  notFound,
+			want: &cliError{Code: exitCodeNotFound, Message: "machine 1 not found", RequestID: "rq-1", HTTPStatus: http.StatusNotFound},
+		},
+		{
+			name: "wrapped unauthorized without payload",
+			err:  fmt.Errorf("unable to find machine: %w", unauthorized),
+			want: &cliError{Code: exitCodeUnauthorized, Message: "unable to find machine: " + unauthorized.Error(), RequestID: "rq-1", HTTPStatus: http.StatusUnauthorized},
+		},
+		{
+			name: "conflict",
+			err:  conflict,
+			want: &cliError{Code: exitCodeConflict, Message: "tenant already exists", RequestID: "rq-1", HTTPStatus: http.StatusConflict},
+		},
+		{
+			name: "forbidden",
+			err:  health.NewHealthDefault(http.StatusForbidden),
+			want: &cliError{Code: exitCodeForbidden, Message: health.NewHealthDefault(http.StatusForbidden).Error(), RequestID: "rq-1", HTTPStatus: http.StatusForbidden},
+		},
+		{
+			name: "bad request",
+			err:  runtime.NewAPIError("unknown error", nil, http.StatusUnprocessableEntity),
+			want: &cliError{Code: exitCodeBadRequest, Message: runtime.NewAPIError("unknown error", nil, http.StatusUnprocessableEntity).Error(), RequestID: "rq-1", HTTPStatus: http.StatusUnprocessableEntity},
+		},
+		{
+			name: "server error",
+			err:  health.NewHealthDefault(http.StatusBadGateway),
+			want: &cliError{Code: exitCodeServerError, Message: health.NewHealthDefault(http.StatusBadGateway).Error(), RequestID: "rq-1", HTTPStatus: http.StatusBadGateway},
+		},
+		{
+			name: "network error",
+			err:  &url.Error{Op: "Get", URL: "http://localhost:1", Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+			want: &cliError{Code: exitCodeNetwork, Message: `Get "http://localhost:1": dial tcp: connection refused`, RequestID: "rq-1"},
+		},
+		{
+			name: "timeout",
+			err:  fmt.Errorf("request timed out: %w", context.DeadlineExceeded),
+			want: &cliError{Code: exitCodeTimeout, Message: "request timed out: context deadline exceeded", RequestID: "rq-1"},
+		},
+		{
+			name: "wait timeout",
+			err:  &waitTimeoutError{msg: "timeout after 1m0s waiting for machine 1 to reach [allocated]"},
+			want: &cliError{Code: exitCodeTimeout, Message: "timeout after 1m0s waiting for machine 1 to reach [allocated]", RequestID: "rq-1"},
+		},
+		{
+			name: "interrupted",
+			err:  fmt.Errorf("interrupted: %w", context.Canceled),
+			want: &cliError{Code: exitCodeInterrupted, Message: "interrupted: context canceled"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := toCLIError(tt.err, "rq-1")
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("diff (+got -want):\n %s", diff)
+			}
+		})
+	}
+}
+
+func Test_requestIDTransport(t *testing.T) {
+	var received []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Request-Id"))
+	}))
+	defer server.Close()
+
+	transport := &requestIDTransport{next: http.DefaultTransport}
+	client := &http.Client{Transport: transport}
+
+	for range 2 {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	require.Len(t, received, 2)
+	assert.NotEmpty(t, received[0])
+	assert.NotEqual(t, received[0], received[1])
+	assert.Equal(t, received[1], transport.lastRequestID())
+
+	var nilTransport *requestIDTransport
+	assert.Empty(t, nilTransport.lastRequestID())
+}
+
+func Test_usageErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "unknown command",
+			args:    []string{"foo"},
+			wantErr: `unknown command "foo" for "metalctl"`,
+		},
+		{
+			name:    "missing required flag",
+			args:    []string{"filesystemlayout", "try", "--size", "s1"},
+			wantErr: `required flag(s) "image" not set`,
+		},
+		{
+			name:    "mutually exclusive flags",
+			args:    []string{"login", "--device-code", "--client-credentials"},
+			wantErr: "if any flags in the group [device-code client-credentials] are set none of the others can be; [client-credentials device-code] were all set",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			defer viper.Reset()
+
+			_, _, config := (&test[any]{}).newMockConfig(t)
+
+			cmd := newRootCmd(config)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			require.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, exitCodeUsage, toCLIError(err, "").Code)
+		})
+	}
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		cmd := &cobra.Command{Use: "test", Args: cobra.ExactArgs(1), RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+		markUsageErrors(cmd)
+		cmd.SetArgs([]string{"a", "b"})
+
+		err := cmd.Execute()
+		require.EqualError(t, err, "accepts 1 arg(s), received 2")
+		assert.Equal(t, exitCodeUsage, toCLIError(err, "").Code)
+	})
+}
@@ -70,7 +70,7 @@ func newFilesystemLayoutCmd(c *config) *cobra.Command {
 }
 
 func (c *fslCmd) Get(id string) (*models.V1FilesystemLayoutResponse, error) {
-	resp, err := c.client.Filesystemlayout().GetFilesystemLayout(fsmodel.NewGetFilesystemLayoutParams().WithID(id), nil)
+	resp, err := c.client.Filesystemlayout().GetFilesystemLayout(fsmodel.NewGetFilesystemLayoutParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +79,7 @@ func (c *fslCmd) Get(id string) (*models.V1FilesystemLayoutResponse, error) {
 }
 
 func (c *fslCmd) List() ([]*models.V1FilesystemLayoutResponse, error) {
-	resp, err := c.client.Filesystemlayout().ListFilesystemLayouts(fsmodel.NewListFilesystemLayoutsParams(), nil)
+	resp, err := c.client.Filesystemlayout().ListFilesystemLayouts(fsmodel.NewListFilesystemLayoutsParams().WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +88,7 @@ func (c *fslCmd) List() ([]*models.V1FilesystemLayoutResponse, error) {
 }
 
 func (c *fslCmd) Delete(id string) (*models.V1FilesystemLayoutResponse, error) {
-	resp, err := c.client.Filesystemlayout().DeleteFilesystemLayout(fsmodel.NewDeleteFilesystemLayoutParams().WithID(id), nil)
+	resp, err := c.client.Filesystemlayout().DeleteFilesystemLayout(fsmodel.NewDeleteFilesystemLayoutParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +97,7 @@ func (c *fslCmd) Delete(id string) (*models.V1FilesystemLayoutResponse, error) {
 }
 
 func (c *fslCmd) Create(rq *models.V1FilesystemLayoutCreateRequest) (*models.V1FilesystemLayoutResponse, error) {
-	resp, err := c.client.Filesystemlayout().CreateFilesystemLayout(fsmodel.NewCreateFilesystemLayoutParams().WithBody(rq), nil)
+	resp, err := c.client.Filesystemlayout().CreateFilesystemLayout(fsmodel.NewCreateFilesystemLayoutParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *fsmodel.CreateFilesystemLayoutConflict
 		if errors.As(err, &r) {
@@ -110,7 +110,7 @@ func (c *fslCmd) Create(rq *models.V1FilesystemLayoutCreateRequest) (*models.V1F
 }
 
 func (c *fslCmd) Update(rq *models.V1FilesystemLayoutUpdateRequest) (*models.V1FilesystemLayoutResponse, error) {
-	resp, err := c.client.Filesystemlayout().UpdateFilesystemLayout(fsmodel.NewUpdateFilesystemLayoutParams().WithBody(rq), nil)
+	resp, err := c.client.Filesystemlayout().UpdateFilesystemLayout(fsmodel.NewUpdateFilesystemLayoutParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +164,7 @@ func (c *fslCmd) filesystemTry() error {
 		Image: &image,
 	}
 
-	resp, err := c.client.Filesystemlayout().TryFilesystemLayout(fsmodel.NewTryFilesystemLayoutParams().WithBody(&try), nil)
+	resp, err := c.client.Filesystemlayout().TryFilesystemLayout(fsmodel.NewTryFilesystemLayoutParams().WithBody(&try).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -178,7 +178,7 @@ func (c *fslCmd) filesystemMatch() error {
 		Filesystemlayout: new(viper.GetString("filesystemlayout")),
 	}
 
-	resp, err := c.client.Filesystemlayout().MatchFilesystemLayout(fsmodel.NewMatchFilesystemLayoutParams().WithBody(&match), nil)
+	resp, err := c.client.Filesystemlayout().MatchFilesystemLayout(fsmodel.NewMatchFilesystemLayoutParams().WithBody(&match).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -126,7 +126,7 @@ ingress:
 }
 
 func (c *firewallCmd) Get(id string) (*models.V1FirewallResponse, error) {
-	resp, err := c.client.Firewall().FindFirewall(firewall.NewFindFirewallParams().WithID(id), nil)
+	resp, err := c.client.Firewall().FindFirewall(firewall.NewFindFirewallParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -150,7 +150,7 @@ func (c *firewallCmd) List() ([]*models.V1FirewallResponse, error) {
 		AllocationHostname: viper.GetString("hostname"),
 		NicsMacAddresses:   macs,
 		Tags:               viper.GetStringSlice("tags"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -163,7 +163,7 @@ func (c *firewallCmd) Delete(_ string) (*models.V1FirewallResponse, error) {
 }
 
 func (c *firewallCmd) Create(rq *models.V1FirewallCreateRequest) (*models.V1FirewallResponse, error) {
-	resp, err := c.client.Firewall().AllocateFirewall(firewall.NewAllocateFirewallParams().WithBody(rq), nil)
+	resp, err := c.client.Firewall().AllocateFirewall(firewall.NewAllocateFirewallParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -216,7 +216,7 @@ func firewallResponseToCreate(r *models.V1FirewallResponse) *models.V1FirewallCr
 }
 
 func (c *firewallCmd) createRequestFromCLI() (*models.V1FirewallCreateRequest, error) {
-	mcr, err := machineCreateRequest()
+	mcr, err := c.machineCreateRequest()
 	if err != nil {
 		return nil, fmt.Errorf("firewall create error:%w", err)
 	}
@@ -295,7 +295,7 @@ func (c *firewallCmd) firewallPureSSH(fwAllocation *models.V1MachineAllocation)
 		}
 		for _, ip := range nw.Ips {
 			if portOpen(ip, "22", time.Second) {
-				err = sshClient("metal", viper.GetString("identity"), ip, 22, nil, false)
+				err = c.sshClient("metal", viper.GetString("identity"), ip, 22, nil, false)
 				if err != nil {
 					return err
 				}
@@ -118,7 +118,7 @@ func (c *config) firmwareList() error {
 	vendor := viper.GetString("vendor")
 	id := viper.GetString("machineid")
 
-	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithKind(&kind).WithBoard(&board).WithVendor(&vendor).WithMachineID(&id), nil)
+	resp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithKind(&kind).WithBoard(&board).WithVendor(&vendor).WithMachineID(&id).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -144,7 +144,7 @@ func (c *config) firmwareRemove() error {
 		WithKind(kind).
 		WithBoard(board).
 		WithVendor(vendor).
-		WithRevision(revision), nil)
+		WithRevision(revision).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -175,7 +175,7 @@ func (c *config) uploadFirmware(kind string, args []string) error {
 		WithBoard(board).
 		WithVendor(vendor).
 		WithRevision(revision).
-		WithFile(runtime.NamedReader(revision, reader)), nil)
+		WithFile(runtime.NamedReader(revision, reader)).WithContext(c.ctx), nil)
 
 	return err
 
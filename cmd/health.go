@@ -12,7 +12,7 @@ func newHealthCmd(c *config) *cobra.Command {
 		Use:   "health",
 		Short: "shows the server health",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			resp, err := c.client.Health().Health(health.NewHealthParams(), nil)
+			resp, err := c.client.Health().Health(health.NewHealthParams().WithContext(c.ctx), nil)
 			if err != nil {
 				var r *health.HealthInternalServerError
 				if errors.As(err, &r) {
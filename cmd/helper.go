@@ -57,7 +57,7 @@ func getAuthContext(kubeconfig string) (*auth.AuthContext, error) {
 	if err != nil {
 		return nil, err
 	}
-	authContext, err := auth.GetAuthContext(kubeconfig, formatContextName(cloudContext, cs.CurrentContext))
+	authContext, err := auth.GetAuthContext(kubeconfig, formatContextName(cloudContext, cs.ActiveContextName()))
 	if err != nil {
 		return nil, err
 	}
@@ -78,7 +78,7 @@ func formatContextName(prefix string, suffix string) string {
 	return contextName
 }
 
-func searchSSHKey() (string, error) {
+func (c *config) searchSSHKey() (string, error) {
 	currentUser, err := user.Current()
 	if err != nil {
 		return "", fmt.Errorf("unable to determine current user for expanding userdata path:%w", err)
@@ -90,8 +90,7 @@ func searchSSHKey() (string, error) {
 		possibleKey := filepath.Join(defaultDir, k)
 		_, err := os.ReadFile(possibleKey)
 		if err == nil {
-			fmt.Printf("using SSH identity: %s. Another identity can be specified with --sshidentity/-p\n",
-				possibleKey)
+			c.log.Info("using ssh identity, another identity can be specified with --sshidentity/-p", "identity", possibleKey)
 			key = possibleKey
 			break
 		}
@@ -75,7 +75,7 @@ func newImageCmd(c *config) *cobra.Command {
 }
 
 func (c imageCmd) Get(id string) (*models.V1ImageResponse, error) {
-	resp, err := c.client.Image().FindImage(image.NewFindImageParams().WithID(id), nil)
+	resp, err := c.client.Image().FindImage(image.NewFindImageParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +91,7 @@ func (c imageCmd) List() ([]*models.V1ImageResponse, error) {
 		Name:           viper.GetString("name"),
 		Os:             viper.GetString("os"),
 		Version:        viper.GetString("version"),
-	}).WithShowUsage(new(viper.GetBool("show-usage"))), nil)
+	}).WithShowUsage(new(viper.GetBool("show-usage"))).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +100,7 @@ func (c imageCmd) List() ([]*models.V1ImageResponse, error) {
 }
 
 func (c imageCmd) Delete(id string) (*models.V1ImageResponse, error) {
-	resp, err := c.client.Image().DeleteImage(image.NewDeleteImageParams().WithID(id), nil)
+	resp, err := c.client.Image().DeleteImage(image.NewDeleteImageParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +109,7 @@ func (c imageCmd) Delete(id string) (*models.V1ImageResponse, error) {
 }
 
 func (c imageCmd) Create(rq *models.V1ImageCreateRequest) (*models.V1ImageResponse, error) {
-	resp, err := c.client.Image().CreateImage(image.NewCreateImageParams().WithBody(rq), nil)
+	resp, err := c.client.Image().CreateImage(image.NewCreateImageParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *image.CreateImageConflict
 		if errors.As(err, &r) {
@@ -122,7 +122,7 @@ func (c imageCmd) Create(rq *models.V1ImageCreateRequest) (*models.V1ImageRespon
 }
 
 func (c imageCmd) Update(rq *models.V1ImageUpdateRequest) (*models.V1ImageResponse, error) {
-	resp, err := c.client.Image().UpdateImage(image.NewUpdateImageParams().WithBody(rq), nil)
+	resp, err := c.client.Image().UpdateImage(image.NewUpdateImageParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +55,7 @@ func newIPCmd(c *config) *cobra.Command {
 			genericcli.Must(cmd.RegisterFlagCompletionFunc("addressfamily", c.comp.IPAddressFamilyCompletion))
 		},
 		ListCmdMutateFn: func(cmd *cobra.Command) {
+			c.addWatchFlags(cmd)
 			cmd.Flags().StringP("ipaddress", "", "", "ipaddress to filter [optional]")
 			cmd.Flags().StringP("project", "", "", "project to filter [optional]")
 			cmd.Flags().StringP("prefix", "", "", "prefix to filter [optional]")
@@ -90,7 +91,7 @@ func newIPCmd(c *config) *cobra.Command {
 }
 
 func (c *ipCmd) Get(id string) (*models.V1IPResponse, error) {
-	resp, err := c.client.IP().FindIP(ip.NewFindIPParams().WithID(id), nil)
+	resp, err := c.client.IP().FindIP(ip.NewFindIPParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +110,7 @@ func (c *ipCmd) List() ([]*models.V1IPResponse, error) {
 		Networkprefix: viper.GetString("prefix"),
 		Tags:          viper.GetStringSlice("tags"),
 		Addressfamily: viper.GetString("addressfamily"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -118,7 +119,7 @@ func (c *ipCmd) List() ([]*models.V1IPResponse, error) {
 }
 
 func (c *ipCmd) Delete(id string) (*models.V1IPResponse, error) {
-	resp, err := c.client.IP().FreeIP(ip.NewFreeIPParams().WithID(id), nil)
+	resp, err := c.client.IP().FreeIP(ip.NewFreeIPParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -128,7 +129,7 @@ func (c *ipCmd) Delete(id string) (*models.V1IPResponse, error) {
 
 func (c *ipCmd) Create(rq *ipAllocateRequest) (*models.V1IPResponse, error) {
 	if rq.SpecificIP == "" {
-		resp, err := c.client.IP().AllocateIP(ip.NewAllocateIPParams().WithBody(rq.V1IPAllocateRequest), nil)
+		resp, err := c.client.IP().AllocateIP(ip.NewAllocateIPParams().WithBody(rq.V1IPAllocateRequest).WithContext(c.ctx), nil)
 		if err != nil {
 			var r *ip.AllocateIPConflict
 			if errors.As(err, &r) {
@@ -140,7 +141,7 @@ func (c *ipCmd) Create(rq *ipAllocateRequest) (*models.V1IPResponse, error) {
 		return resp.Payload, nil
 	}
 
-	resp, err := c.client.IP().AllocateSpecificIP(ip.NewAllocateSpecificIPParams().WithIP(rq.SpecificIP).WithBody(rq.V1IPAllocateRequest), nil)
+	resp, err := c.client.IP().AllocateSpecificIP(ip.NewAllocateSpecificIPParams().WithIP(rq.SpecificIP).WithBody(rq.V1IPAllocateRequest).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *ip.AllocateSpecificIPConflict
 		if errors.As(err, &r) {
@@ -153,7 +154,7 @@ func (c *ipCmd) Create(rq *ipAllocateRequest) (*models.V1IPResponse, error) {
 }
 
 func (c *ipCmd) Update(rq *models.V1IPUpdateRequest) (*models.V1IPResponse, error) {
-	resp, err := c.client.IP().UpdateIP(ip.NewUpdateIPParams().WithBody(rq), nil)
+	resp, err := c.client.IP().UpdateIP(ip.NewUpdateIPParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -215,7 +216,7 @@ func (c *ipCmd) createRequestFromCLI() (*ipAllocateRequest, error) {
 // non-generic command handling
 
 func (c *ipCmd) ipIssues() error {
-	ml, err := c.client.Machine().ListMachines(machine.NewListMachinesParams(), nil)
+	ml, err := c.client.Machine().ListMachines(machine.NewListMachinesParams().WithContext(c.ctx), nil)
 	if err != nil {
 		return fmt.Errorf("machine list error:%w", err)
 	}
@@ -37,7 +37,7 @@ func newLoginCmd(c *config) *cobra.Command {
 					return err
 				}
 				console = os.Stdout
-				handler = auth.NewUpdateKubeConfigHandler(viper.GetString("kubeconfig"), console, auth.WithContextName(formatContextName(cloudContext, cs.CurrentContext)))
+				handler = auth.NewUpdateKubeConfigHandler(viper.GetString("kubeconfig"), console, auth.WithContextName(formatContextName(cloudContext, cs.ActiveContextName())))
 			}
 
 			ctx := api.MustDefaultContext()
@@ -45,59 +45,88 @@ func newLoginCmd(c *config) *cobra.Command {
 			if ctx.IssuerType == "generic" {
 				scopes = auth.GenericScopes
 			} else if ctx.CustomScopes != "" {
-				cs := strings.Split(ctx.CustomScopes, ",")
-				for i := range cs {
-					cs[i] = strings.TrimSpace(cs[i])
-				}
-				scopes = cs
+				scopes = splitScopes(ctx.CustomScopes)
 			}
 
-			config := auth.Config{
-				ClientID:     ctx.ClientID,
-				ClientSecret: ctx.ClientSecret,
-				IssuerURL:    ctx.IssuerURL,
-				Scopes:       scopes,
-				TokenHandler: handler,
-				Console:      console,
-				Debug:        viper.GetBool("debug"),
-				Log:          c.log,
+			clientSecret, err := contextClientSecret(ctx)
+			if err != nil {
+				return err
 			}
 
 			_, _ = fmt.Fprintln(c.out)
 
-			err := auth.OIDCFlow(config)
-			if err != nil {
-				return err
-			}
+			if viper.GetBool("client-credentials") {
+				cs, err := api.GetContexts()
+				if err != nil {
+					return err
+				}
 
-			resp, err := c.client.Version().Info(version.NewInfoParams(), clientNoAuth())
-			if err != nil {
-				return err
-			}
-			if resp.Payload != nil && resp.Payload.MinClientVersion != nil {
-				minVersion := *resp.Payload.MinClientVersion
-				parsedMinVersion, err := semver.NewVersion(minVersion)
+				token, err := api.AcquireClientCredentialsToken(c.ctx, cs.ActiveContextName(), api.ClientCredentialsConfig{
+					IssuerURL:    ctx.IssuerURL,
+					ClientID:     ctx.ClientID,
+					ClientSecret: clientSecret,
+					// the default scopes are meant for users, service accounts only request the custom scopes
+					Scopes: splitScopes(ctx.CustomScopes),
+					ApiURL: contextAPIURL(ctx),
+				})
 				if err != nil {
-					return fmt.Errorf("required metalctl minimum version:%q is not semver parsable:%w", minVersion, err)
+					return err
 				}
 
-				// This is a developer build
-				if !strings.HasPrefix(v.Version, "v") {
-					return nil
+				if viper.GetBool("print-only") {
+					_, _ = fmt.Fprintln(c.out, token.Token)
+				} else {
+					_, _ = fmt.Fprintf(c.out, "Successfully acquired token for client %s, the token is cached and re-acquired on expiration\n", ctx.ClientID)
+				}
+			} else if viper.GetBool("device-code") {
+				// the user code must be shown even if only the token is printed to stdout
+				prompt := c.out
+				if viper.GetBool("print-only") {
+					prompt = os.Stderr
+				}
+
+				err := api.DeviceCodeFlow(c.ctx, api.DeviceCodeConfig{
+					IssuerURL:    ctx.IssuerURL,
+					ClientID:     ctx.ClientID,
+					ClientSecret: clientSecret,
+					Scopes:       scopes,
+					TokenHandler: handler,
+					Console:      prompt,
+				})
+				if err != nil {
+					return err
+				}
+			} else {
+				config := auth.Config{
+					ClientID:     ctx.ClientID,
+					ClientSecret: clientSecret,
+					IssuerURL:    ctx.IssuerURL,
+					Scopes:       scopes,
+					TokenHandler: handler,
+					Console:      console,
+					Debug:        viper.GetBool("debug"),
+					Log:          c.log,
 				}
 
-				thisVersion, err := semver.NewVersion(v.Version)
+				err := auth.OIDCFlow(config)
 				if err != nil {
-					return fmt.Errorf("metalctl version:%q is not semver parsable:%w", v.Version, err)
+					return err
 				}
+			}
 
-				if thisVersion.LessThan(parsedMinVersion) {
-					return fmt.Errorf("your metalctl version:%s is smaller than the required minimum version:%s, please run `metalctl update do` to get this version", thisVersion, minVersion)
+			resp, err := c.client.Version().Info(version.NewInfoParams().WithContext(c.ctx), clientNoAuth())
+			if err != nil {
+				return err
+			}
+			if resp.Payload != nil && resp.Payload.MinClientVersion != nil {
+				warning, err := checkMinClientVersion(*resp.Payload.MinClientVersion)
+				if err != nil {
+					return err
 				}
 
-				if !thisVersion.Equal(parsedMinVersion) {
+				if warning != "" {
 					_, _ = fmt.Fprintln(c.out)
-					_, _ = fmt.Fprintf(c.out, "WARNING: Your metalctl version %q might not compatible with the metal-api (supported version is %q). Please run `metalctl update do` to update to the supported version.", thisVersion, minVersion)
+					_, _ = fmt.Fprintf(c.out, "WARNING: %s", warning)
 					_, _ = fmt.Fprintln(c.out)
 				}
 			}
@@ -107,5 +136,72 @@ func newLoginCmd(c *config) *cobra.Command {
 		},
 	}
 	loginCmd.Flags().Bool("print-only", false, "If true, the token is printed to stdout")
+	loginCmd.Flags().Bool("device-code", false, "If true, the device authorization grant is used instead of a local browser callback, useful on remote hosts without a browser")
+	loginCmd.Flags().Bool("client-credentials", false, "If true, the client credentials grant is used with the client id and secret of the context, intended for service accounts e.g. in ci pipelines. The token is cached separately from the kube-config.")
+
+	loginCmd.MarkFlagsMutuallyExclusive("device-code", "client-credentials")
 	return loginCmd
 }
+
+// checkMinClientVersion checks if this metalctl version satisfies the minimum client version required by the metal-api.
+// A returned warning indicates that the versions are compatible but not equal.
+func checkMinClientVersion(minVersion string) (string, error) {
+	parsedMinVersion, err := semver.NewVersion(minVersion)
+	if err != nil {
+		return "", fmt.Errorf("required metalctl minimum version:%q is not semver parsable:%w", minVersion, err)
+	}
+
+	// This is a developer build
+	if !strings.HasPrefix(v.Version, "v") {
+		return "", nil
+	}
+
+	thisVersion, err := semver.NewVersion(v.Version)
+	if err != nil {
+		return "", fmt.Errorf("metalctl version:%q is not semver parsable:%w", v.Version, err)
+	}
+
+	if thisVersion.LessThan(parsedMinVersion) {
+		return "", fmt.Errorf("your metalctl version:%s is smaller than the required minimum version:%s, please run `metalctl update do` to get this version", thisVersion, minVersion)
+	}
+
+	if !thisVersion.Equal(parsedMinVersion) {
+		return fmt.Sprintf("Your metalctl version %q might not compatible with the metal-api (supported version is %q). Please run `metalctl update do` to update to the supported version.", thisVersion, minVersion), nil
+	}
+
+	return "", nil
+}
+
+// splitScopes splits a comma separated list of scopes
+func splitScopes(scopes string) []string {
+	var res []string
+	for s := range strings.SplitSeq(scopes, ",") {
+		s = strings.TrimSpace(s)
+		if s != "" {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// contextClientSecret returns the client secret of the context, which is retrieved from the credential helper if not set explicitly
+func contextClientSecret(ctx api.Context) (string, error) {
+	if ctx.ClientSecret != "" || ctx.CredentialHelper == nil {
+		return ctx.ClientSecret, nil
+	}
+
+	creds, err := ctx.CredentialHelper.Credentials()
+	if err != nil {
+		return "", err
+	}
+
+	return creds.ClientSecret, nil
+}
+
+// contextAPIURL returns the url of the metal-api, the api-url flag takes precedence over the context
+func contextAPIURL(ctx api.Context) string {
+	if apiURL := viper.GetString("api-url"); apiURL != "" {
+		return apiURL
+	}
+	return ctx.ApiURL
+}
@@ -15,7 +15,22 @@ func newLogoutCmd(c *config) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := api.MustDefaultContext()
 
-			err := auth.Logout(&auth.LogoutParams{
+			cs, err := api.GetContexts()
+			if err != nil {
+				return err
+			}
+
+			removed, err := api.RemoveClientCredentialsToken(cs.ActiveContextName())
+			if err != nil {
+				return err
+			}
+			if removed {
+				// there is no sso session for the client credentials grant
+				_, _ = fmt.Fprintln(c.out, "Cached client credentials token successfully removed. Token is not revoked and is valid until expiration.")
+				return nil
+			}
+
+			err = auth.Logout(&auth.LogoutParams{
 				IssuerURL: ctx.IssuerURL,
 				Logger:    c.log,
 			})
@@ -38,6 +38,15 @@ type machineCmd struct {
 }
 
 func (c *machineCmd) listCmdFlags(cmd *cobra.Command, lastEventErrorThresholdDefault time.Duration) {
+	c.filterCmdFlags(cmd)
+
+	cmd.Flags().Duration("last-event-error-threshold", lastEventErrorThresholdDefault, "the duration up to how long in the past a machine last event error will be counted as an issue [optional]")
+
+	cmd.Long = cmd.Short + "\n" + api.EmojiHelpText()
+}
+
+// filterCmdFlags adds the flags for filtering machines, which are used by machineFindRequestFromCLI
+func (c *machineCmd) filterCmdFlags(cmd *cobra.Command) {
 	listFlagCompletions := []struct {
 		flagName string
 		f        func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
@@ -77,7 +86,6 @@ func (c *machineCmd) listCmdFlags(cmd *cobra.Command, lastEventErrorThresholdDef
 	cmd.Flags().String("hostname", "", "allocation hostname to filter [optional]")
 	cmd.Flags().String("mac", "", "mac to filter [optional]")
 	cmd.Flags().StringSlice("tags", []string{}, "tags to filter, use it like: --tags \"tag1,tag2\" or --tags \"tag3\".")
-	cmd.Flags().Duration("last-event-error-threshold", lastEventErrorThresholdDefault, "the duration up to how long in the past a machine last event error will be counted as an issue [optional]")
 	cmd.Flags().String("role", "", "allocation role to filter [optional]")
 	cmd.Flags().String("board-part-number", "", "fru board part number to filter [optional]")
 	cmd.Flags().String("manufacturer", "", "fru manufacturer to filter [optional]")
@@ -94,8 +102,6 @@ func (c *machineCmd) listCmdFlags(cmd *cobra.Command, lastEventErrorThresholdDef
 	for _, c := range listFlagCompletions {
 		genericcli.Must(cmd.RegisterFlagCompletionFunc(c.flagName, c.f))
 	}
-
-	cmd.Long = cmd.Short + "\n" + api.EmojiHelpText()
 }
 
 func newMachineCmd(c *config) *cobra.Command {
@@ -118,6 +124,7 @@ func newMachineCmd(c *config) *cobra.Command {
 		ListPrinter:          func() printers.Printer { return c.listPrinter },
 		CreateCmdMutateFn: func(cmd *cobra.Command) {
 			c.addMachineCreateFlags(cmd, "machine")
+			addMachineWaitFlags(cmd)
 			cmd.Aliases = []string{"allocate"}
 			cmd.Example = `machine create can be done in two different ways:
 
@@ -161,6 +168,21 @@ Once created the machine installation can not be modified anymore.
 		},
 		ListCmdMutateFn: func(cmd *cobra.Command) {
 			w.listCmdFlags(cmd, 1*time.Hour)
+
+			runE := cmd.RunE
+			cmd.RunE = func(cmd *cobra.Command, args []string) error {
+				if !w.requiresMachineIPMI() {
+					return runE(cmd, args)
+				}
+
+				printer := c.listPrinter
+				defer func() { c.listPrinter = printer }()
+				c.listPrinter = &machineIPMIPrinter{c: w, printer: printer}
+
+				return runE(cmd, args)
+			}
+
+			c.addWatchFlags(cmd)
 		},
 		UpdateCmdMutateFn: func(cmd *cobra.Command) {
 			cmd.Flags().String("description", "", "the description of the machine [optional]")
@@ -425,6 +447,12 @@ In case the machine did not register properly a direct ipmi console access is av
 	genericcli.Must(machineIssuesCmd.RegisterFlagCompletionFunc("only", c.comp.IssueTypeCompletion))
 
 	machineLogsCmd.Flags().Duration("last-event-error-threshold", 7*24*time.Hour, "the duration up to how long in the past a machine last event error will be counted as an issue [optional]")
+	machineLogsCmd.Flags().BoolP("follow", "f", false, "stream new provisioning events until the machine phones home or is in a crash loop.")
+	machineLogsCmd.Flags().Duration("interval", 5*time.Second, "the interval for polling the metal-api with --follow.")
+	machineLogsCmd.Flags().Bool("sel", false, "interleave the system event log of the bmc with --follow, requires ipmitool (admin only).")
+	machineLogsCmd.Flags().String("sel-timezone", "UTC", "time zone of the system event log timestamps, bmcs usually run in UTC. Use e.g. Europe/Berlin or Local for bmcs running in a local time zone.")
+	machineLogsCmd.Flags().StringP("ipmiuser", "", "", "overwrite ipmi user for --sel (admin only).")
+	machineLogsCmd.Flags().StringP("ipmipassword", "", "", "overwrite ipmi password for --sel (admin only).")
 
 	machineConsolePasswordCmd.Flags().StringP("reason", "", "", "a short description why access to the consolepassword is required")
 
@@ -460,6 +488,7 @@ In case the machine did not register properly a direct ipmi console access is av
 
 	machineReinstallCmd.Flags().StringP("image", "", "", "id of the image to get installed. [required]")
 	machineReinstallCmd.Flags().StringP("description", "d", "", "description of the reinstallation. [optional]")
+	addMachineWaitFlags(machineReinstallCmd)
 	genericcli.Must(machineReinstallCmd.MarkFlagRequired("image"))
 
 	machineConsoleCmd.Flags().StringP("sshidentity", "i", "", "SSH key file, if not given the default ssh key will be used if present [optional].")
@@ -486,6 +515,8 @@ In case the machine did not register properly a direct ipmi console access is av
 		machineReserveCmd,
 		machineLockCmd,
 		machineReinstallCmd,
+		newMachineWaitCmd(w),
+		newMachineEventsCmd(w),
 	)
 }
 
@@ -553,7 +584,7 @@ MODE can be omitted or one of:
 }
 
 func (c *machineCmd) Get(id string) (*models.V1MachineResponse, error) {
-	resp, err := c.client.Machine().FindMachine(machine.NewFindMachineParams().WithID(id), nil)
+	resp, err := c.client.Machine().FindMachine(machine.NewFindMachineParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -562,7 +593,7 @@ func (c *machineCmd) Get(id string) (*models.V1MachineResponse, error) {
 }
 
 func (c *machineCmd) List() ([]*models.V1MachineResponse, error) {
-	resp, err := c.client.Machine().FindMachines(machine.NewFindMachinesParams().WithBody(machineFindRequestFromCLI()), nil)
+	resp, err := c.client.Machine().FindMachines(machine.NewFindMachinesParams().WithBody(machineFindRequestFromCLI()).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -570,6 +601,52 @@ func (c *machineCmd) List() ([]*models.V1MachineResponse, error) {
 	return resp.Payload, nil
 }
 
+// requiresMachineIPMI returns true if the selected columns of the machine table contain bmc or fru data
+func (c *machineCmd) requiresMachineIPMI() bool {
+	switch format, _, _ := strings.Cut(viper.GetString("output-format"), "="); format {
+	case "table", "wide", "markdown", "csv", "tsv":
+	default:
+		return false
+	}
+
+	columns := viper.GetStringSlice("columns")
+	if len(columns) == 0 {
+		columns = c.tableColumns[tableprinters.TableName([]*models.V1MachineResponse{})]
+	}
+
+	return tableprinters.RequiresMachineIPMI(columns)
+}
+
+// machineIPMIPrinter prints listed machines together with their ipmi data, which is required for the bmc and fru columns
+type machineIPMIPrinter struct {
+	c       *machineCmd
+	printer printers.Printer
+}
+
+func (p *machineIPMIPrinter) Print(data any) error {
+	machines, ok := data.([]*models.V1MachineResponse)
+	if !ok {
+		return p.printer.Print(data)
+	}
+
+	resp, err := p.c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(machineFindRequestFromCLI()).WithContext(p.c.ctx), nil)
+	if err != nil {
+		return err
+	}
+
+	ipmi := map[string]*models.V1MachineIPMI{}
+	for _, m := range resp.Payload {
+		if m.Ipmi != nil {
+			ipmi[pointer.SafeDeref(m.ID)] = m.Ipmi
+		}
+	}
+
+	return p.printer.Print(&tableprinters.MachinesWithIPMI{
+		Machines: machines,
+		IPMI:     ipmi,
+	})
+}
+
 func machineFindRequestFromCLI() *models.V1MachineFindRequest {
 	var macs []string
 	if viper.IsSet("mac") {
@@ -609,7 +686,7 @@ func (c *machineCmd) Delete(id string) (*models.V1MachineResponse, error) {
 			return nil, fmt.Errorf("remove-from-database is set but you forgot to add --%s", forceFlag)
 		}
 
-		resp, err := c.client.Machine().DeleteMachine(machine.NewDeleteMachineParams().WithID(id), nil)
+		resp, err := c.client.Machine().DeleteMachine(machine.NewDeleteMachineParams().WithID(id).WithContext(c.ctx), nil)
 		if err != nil {
 			return nil, err
 		}
@@ -617,7 +694,7 @@ func (c *machineCmd) Delete(id string) (*models.V1MachineResponse, error) {
 		return resp.Payload, nil
 	}
 
-	resp, err := c.client.Machine().FreeMachine(machine.NewFreeMachineParams().WithID(id), nil)
+	resp, err := c.client.Machine().FreeMachine(machine.NewFreeMachineParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -626,16 +703,22 @@ func (c *machineCmd) Delete(id string) (*models.V1MachineResponse, error) {
 }
 
 func (c *machineCmd) Create(rq *models.V1MachineAllocateRequest) (*models.V1MachineResponse, error) {
-	resp, err := c.client.Machine().AllocateMachine(machine.NewAllocateMachineParams().WithBody(rq), nil)
+	started := time.Now()
+
+	resp, err := c.client.Machine().AllocateMachine(machine.NewAllocateMachineParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
 
+	if viper.GetBool("wait") {
+		return c.waitForMachine(pointer.SafeDeref(resp.Payload.ID), provisionWait(started))
+	}
+
 	return resp.Payload, nil
 }
 
 func (c *machineCmd) Update(rq *models.V1MachineUpdateRequest) (*models.V1MachineResponse, error) {
-	resp, err := c.client.Machine().UpdateMachine(machine.NewUpdateMachineParams().WithBody(rq), nil)
+	resp, err := c.client.Machine().UpdateMachine(machine.NewUpdateMachineParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -705,7 +788,7 @@ func machineResponseToUpdate(r *models.V1MachineResponse) *models.V1MachineUpdat
 }
 
 func (c *machineCmd) createRequestFromCLI() (*models.V1MachineAllocateRequest, error) {
-	mcr, err := machineCreateRequest()
+	mcr, err := c.machineCreateRequest()
 	if err != nil {
 		return nil, fmt.Errorf("machine create error:%w", err)
 	}
@@ -713,7 +796,7 @@ func (c *machineCmd) createRequestFromCLI() (*models.V1MachineAllocateRequest, e
 	return mcr, nil
 }
 
-func machineCreateRequest() (*models.V1MachineAllocateRequest, error) {
+func (c *config) machineCreateRequest() (*models.V1MachineAllocateRequest, error) {
 	var (
 		keys       []string
 		dnsServers []*models.V1DNSServer
@@ -733,7 +816,7 @@ func machineCreateRequest() (*models.V1MachineAllocateRequest, error) {
 	}
 
 	if len(sshPublicKeyArgument) == 0 {
-		sshKey, err := searchSSHKey()
+		sshKey, err := c.searchSSHKey()
 		if err != nil {
 			return nil, err
 		}
@@ -847,7 +930,7 @@ func (c *machineCmd) machineConsolePassword(args []string) error {
 	resp, err := c.client.Machine().GetMachineConsolePassword(machine.NewGetMachineConsolePasswordParams().WithBody(&models.V1MachineConsolePasswordRequest{
 		ID:     &id,
 		Reason: new(viper.GetString("reason")),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -863,7 +946,7 @@ func (c *machineCmd) machinePowerOn(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().MachineOn(machine.NewMachineOnParams().WithID(id).WithBody(emptyBody), nil)
+	resp, err := c.client.Machine().MachineOn(machine.NewMachineOnParams().WithID(id).WithBody(emptyBody).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -877,7 +960,7 @@ func (c *machineCmd) machinePowerOff(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().MachineOff(machine.NewMachineOffParams().WithID(id).WithBody(emptyBody), nil)
+	resp, err := c.client.Machine().MachineOff(machine.NewMachineOffParams().WithID(id).WithBody(emptyBody).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -891,7 +974,7 @@ func (c *machineCmd) machinePowerReset(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().MachineReset(machine.NewMachineResetParams().WithID(id).WithBody(emptyBody), nil)
+	resp, err := c.client.Machine().MachineReset(machine.NewMachineResetParams().WithID(id).WithBody(emptyBody).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -905,7 +988,7 @@ func (c *machineCmd) machinePowerCycle(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().MachineCycle(machine.NewMachineCycleParams().WithID(id).WithBody(emptyBody), nil)
+	resp, err := c.client.Machine().MachineCycle(machine.NewMachineCycleParams().WithID(id).WithBody(emptyBody).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -948,7 +1031,7 @@ func (c *machineCmd) firmwareData(args []string) (*models.V1MachineIPMIResponse,
 		return nil, "", "", err
 	}
 
-	resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id), nil)
+	resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -966,7 +1049,7 @@ func (c *machineCmd) firmwareData(args []string) (*models.V1MachineIPMIResponse,
 }
 
 func (c *machineCmd) machineUpdateFirmware(kind string, machineID, vendor, board, revision, currentVersion string) error {
-	firmwareResp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithKind(&kind), nil)
+	firmwareResp, err := c.client.Firmware().ListFirmwares(firmware.NewListFirmwaresParams().WithKind(&kind).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1040,7 +1123,7 @@ func (c *machineCmd) machineUpdateFirmware(kind string, machineID, vendor, board
 		Description: &description,
 		Kind:        &kindString,
 		Revision:    &revision,
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1054,7 +1137,7 @@ func (c *machineCmd) machineBootBios(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().MachineBios(machine.NewMachineBiosParams().WithID(id).WithBody(emptyBody), nil)
+	resp, err := c.client.Machine().MachineBios(machine.NewMachineBiosParams().WithID(id).WithBody(emptyBody).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1068,7 +1151,7 @@ func (c *machineCmd) machineBootDisk(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().MachineDisk(machine.NewMachineDiskParams().WithID(id).WithBody(emptyBody), nil)
+	resp, err := c.client.Machine().MachineDisk(machine.NewMachineDiskParams().WithID(id).WithBody(emptyBody).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1082,7 +1165,7 @@ func (c *machineCmd) machineBootPxe(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().MachinePxe(machine.NewMachinePxeParams().WithID(id).WithBody(emptyBody), nil)
+	resp, err := c.client.Machine().MachinePxe(machine.NewMachinePxeParams().WithID(id).WithBody(emptyBody).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1097,7 +1180,7 @@ func (c *machineCmd) machineIdentifyOn(args []string) error {
 	}
 
 	description := new(viper.GetString("description"))
-	resp, err := c.client.Machine().ChassisIdentifyLEDOn(machine.NewChassisIdentifyLEDOnParams().WithID(id).WithBody(emptyBody).WithDescription(description), nil)
+	resp, err := c.client.Machine().ChassisIdentifyLEDOn(machine.NewChassisIdentifyLEDOnParams().WithID(id).WithBody(emptyBody).WithDescription(description).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1112,7 +1195,7 @@ func (c *machineCmd) machineIdentifyOff(args []string) error {
 	}
 
 	description := new(viper.GetString("description"))
-	resp, err := c.client.Machine().ChassisIdentifyLEDOff(machine.NewChassisIdentifyLEDOffParams().WithID(id).WithBody(emptyBody).WithDescription(description), nil)
+	resp, err := c.client.Machine().ChassisIdentifyLEDOff(machine.NewChassisIdentifyLEDOffParams().WithID(id).WithBody(emptyBody).WithDescription(description).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1130,7 +1213,7 @@ func (c *machineCmd) machineReserve(args []string) error {
 		resp, err := c.client.Machine().SetMachineState(machine.NewSetMachineStateParams().WithID(id).WithBody(&models.V1MachineState{
 			Description: new(""),
 			Value:       new(models.V1MachineStateValueEmpty),
-		}), nil)
+		}).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -1141,7 +1224,7 @@ func (c *machineCmd) machineReserve(args []string) error {
 	resp, err := c.client.Machine().SetMachineState(machine.NewSetMachineStateParams().WithID(id).WithBody(&models.V1MachineState{
 		Description: new(viper.GetString("description")),
 		Value:       new(models.V1MachineStateValueRESERVED),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1159,7 +1242,7 @@ func (c *machineCmd) machineLock(args []string) error {
 		resp, err := c.client.Machine().SetMachineState(machine.NewSetMachineStateParams().WithID(id).WithBody(&models.V1MachineState{
 			Description: new(""),
 			Value:       new(models.V1MachineStateValueEmpty),
-		}), nil)
+		}).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -1170,7 +1253,7 @@ func (c *machineCmd) machineLock(args []string) error {
 	resp, err := c.client.Machine().SetMachineState(machine.NewSetMachineStateParams().WithID(id).WithBody(&models.V1MachineState{
 		Description: new(viper.GetString("description")),
 		Value:       new(models.V1MachineStateValueLOCKED),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1184,15 +1267,25 @@ func (c *machineCmd) machineReinstall(args []string) error {
 		return err
 	}
 
+	started := time.Now()
+
 	resp, err := c.client.Machine().ReinstallMachine(machine.NewReinstallMachineParams().WithID(id).WithBody(&models.V1MachineReinstallRequest{
 		ID:          new(id),
 		Description: viper.GetString("description"),
 		Imageid:     new(viper.GetString("image")),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
 
+	if viper.GetBool("wait") {
+		m, err := c.waitForMachine(id, provisionWait(started))
+		if err != nil {
+			return err
+		}
+		return c.listPrinter.Print(m)
+	}
+
 	return c.listPrinter.Print(resp.Payload)
 }
 
@@ -1202,7 +1295,10 @@ func (c *machineCmd) machineLogs(args []string) error {
 		return err
 	}
 
-	// FIXME add ipmi sel as well
+	if viper.GetBool("follow") {
+		return c.machineLogsFollow(id)
+	}
+
 	resp, err := c.Get(id)
 	if err != nil {
 		return err
@@ -1213,8 +1309,13 @@ func (c *machineCmd) machineLogs(args []string) error {
 		return err
 	}
 
-	if pointer.SafeDeref(resp.Events).LastErrorEvent != nil {
-		timeSince := time.Since(time.Time(resp.Events.LastErrorEvent.Time))
+	return c.printLastErrorEvent(resp)
+}
+
+// printLastErrorEvent prints the last error event of the machine, if it happened within the last event error threshold
+func (c *machineCmd) printLastErrorEvent(m *models.V1MachineResponse) error {
+	if pointer.SafeDeref(m.Events).LastErrorEvent != nil {
+		timeSince := time.Since(time.Time(m.Events.LastErrorEvent.Time))
 		if timeSince > viper.GetDuration("last-event-error-threshold") {
 			return nil
 		}
@@ -1223,7 +1324,7 @@ func (c *machineCmd) machineLogs(args []string) error {
 		_, _ = fmt.Fprintf(c.out, "Recent last error (%s ago):\n", timeSince.String())
 		_, _ = fmt.Fprintln(c.out)
 
-		return c.listPrinter.Print(resp.Events.LastErrorEvent)
+		return c.listPrinter.Print(m.Events.LastErrorEvent)
 	}
 
 	return nil
@@ -1242,7 +1343,7 @@ func (c *machineCmd) machineConsole(args []string) error {
 			return fmt.Errorf("unable to locate ipmitool in path")
 		}
 
-		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id), nil)
+		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -1303,7 +1404,7 @@ func (c *machineCmd) machineConsole(args []string) error {
 		token = authContext.IDToken
 	}
 
-	err = sshClient(id, viper.GetString("sshidentity"), parsedurl.Host, bmcConsolePort, &token, viper.GetBool("admin"))
+	err = c.sshClient(id, viper.GetString("sshidentity"), parsedurl.Host, bmcConsolePort, &token, viper.GetBool("admin"))
 	if err != nil {
 		return fmt.Errorf("machine console error:%w", err)
 	}
@@ -1318,7 +1419,7 @@ func (c *machineCmd) machineIpmi(args []string) error {
 			return err
 		}
 
-		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id), nil)
+		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -1331,7 +1432,7 @@ func (c *machineCmd) machineIpmi(args []string) error {
 		return err
 	}
 
-	resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(machineFindRequestFromCLI()), nil)
+	resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(machineFindRequestFromCLI()).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1353,14 +1454,14 @@ func (c *machineCmd) machineIpmiChassis(args []string) error {
 			return err
 		}
 
-		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id), nil)
+		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
 
 		machines = pointer.WrapInSlice(resp.Payload)
 	} else {
-		resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(machineFindRequestFromCLI()), nil)
+		resp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(machineFindRequestFromCLI()).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -1404,7 +1505,7 @@ func (c *machineCmd) machineIpmiChassis(args []string) error {
 }
 
 func (c *machineCmd) machineIssuesList() error {
-	issuesResp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams(), nil)
+	issuesResp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams().WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1432,7 +1533,7 @@ func (c *machineCmd) machineIssuesEvaluate(args []string) error {
 		}
 	}
 
-	issuesResp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams(), nil)
+	issuesResp, err := c.client.Machine().ListIssues(machine.NewListIssuesParams().WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1469,21 +1570,21 @@ func (c *machineCmd) machineIssuesEvaluate(args []string) error {
 		Omit:               viper.GetStringSlice("omit"),
 		Only:               viper.GetStringSlice("only"),
 		Severity:           pointer.PointerOrNil(viper.GetString("severity")),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
 
 	var machines []*models.V1MachineIPMIResponse
 	if len(args) > 0 {
-		machineResp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id), nil)
+		machineResp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
 
 		machines = append(machines, machineResp.Payload)
 	} else {
-		machinesResp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(machineFindRequestFromCLI()), nil)
+		machinesResp, err := c.client.Machine().FindIPMIMachines(machine.NewFindIPMIMachinesParams().WithBody(machineFindRequestFromCLI()).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -1514,53 +1615,12 @@ func (c *machineCmd) machineIpmiEvents(args []string) error {
 		return err
 	}
 
-	path, err := exec.LookPath("ipmitool")
-	if err != nil {
-		return fmt.Errorf("unable to locate ipmitool in path")
-	}
-
-	resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id), nil)
-	if err != nil {
-		return err
-	}
-
-	ipmi := resp.Payload.Ipmi
-	intf := "lanplus"
-	if *ipmi.Interface != "" {
-		intf = *ipmi.Interface
-	}
-	// -I lanplus  -H 192.168.2.19 -U ADMIN -P ADMIN sol activate
-	hostAndPort := strings.Split(*ipmi.Address, ":")
-	if len(hostAndPort) < 2 {
-		hostAndPort = append(hostAndPort, "623")
-	}
-	usr := *ipmi.User
-	if *ipmi.User == "" {
-		_, _ = fmt.Fprintf(c.out, "no ipmi user stored, please specify with --ipmiuser\n")
-	}
-	ipmiuser := viper.GetString("ipmiuser")
-	if ipmiuser != "" {
-		usr = ipmiuser
-	}
-
-	password := *ipmi.Password
-	if *ipmi.Password == "" {
-		_, _ = fmt.Fprintf(c.out, "no ipmi password stored, please specify with --ipmipassword\n")
-	}
-	ipmipassword := viper.GetString("ipmipassword")
-	if ipmipassword != "" {
-		password = ipmipassword
-	}
-	err = os.Setenv("IPMITOOL_PASSWORD", password)
+	ipmitool, err := c.newIpmitool(id)
 	if err != nil {
 		return err
 	}
-	defer func() {
-		_ = os.Unsetenv("IPMITOOL_PASSWORD")
-	}()
 
-	cmdArgs := []string{"-I", intf, "-H", hostAndPort[0], "-p", hostAndPort[1], "-U", usr, "-E", "sel", "list", "last", viper.GetString("last")}
-	cmd := exec.Command(path, cmdArgs...)
+	cmd := ipmitool.command(c.ctx, "sel", "list", "last", viper.GetString("last"))
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stdout
 
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/metal-stack/metal-lib/pkg/genericcli"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+	"github.com/metal-stack/metalctl/cmd/tableprinters"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func newMachineEventsCmd(c *machineCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "display the provisioning events of many machines in one timeline",
+		Long: `lists the provisioning events of all machines matching the filters, oldest first.
+The events are merged into a single timeline, such that issues affecting many machines at once become visible.`,
+		Example: `metalctl machine events --partition fra-equ01 --since 2h
+metalctl machine events --rack rack-1 --event "Phoned Home" --event Crashed -o wide
+metalctl machine events --since 0 --message "(?i)timeout"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.machineEvents()
+		},
+	}
+
+	c.filterCmdFlags(cmd)
+
+	cmd.Flags().Duration("since", 1*time.Hour, "only show events of this recent duration, 0 shows all events.")
+	cmd.Flags().StringSlice("event", nil, "event types to include, case-insensitive [optional]")
+	cmd.Flags().String("message", "", "regular expression the event message must match [optional]")
+
+	genericcli.Must(cmd.RegisterFlagCompletionFunc("event", cobra.FixedCompletions([]string{
+		"Alive",
+		"Crashed",
+		"Installing",
+		"Phoned Home",
+		"Planned Reboot",
+		"PXE Booting",
+		"Preparing",
+		"Registering",
+		"Booting New Kernel",
+		"Waiting",
+	}, cobra.ShellCompDirectiveNoFileComp)))
+
+	return cmd
+}
+
+func (c *machineCmd) machineEvents() error {
+	var (
+		since      time.Time
+		eventTypes = viper.GetStringSlice("event")
+		message    *regexp.Regexp
+	)
+
+	if d := viper.GetDuration("since"); d > 0 {
+		since = time.Now().Add(-d)
+	}
+
+	if expr := viper.GetString("message"); expr != "" {
+		var err error
+		message, err = regexp.Compile(expr)
+		if err != nil {
+			return &usageError{err: fmt.Errorf("invalid message regular expression: %w", err)}
+		}
+	}
+
+	machines, err := c.List()
+	if err != nil {
+		return err
+	}
+
+	var events []*tableprinters.MachineEvent
+	for _, m := range machines {
+		for _, e := range pointer.SafeDeref(m.Events).Log {
+			if time.Time(e.Time).Before(since) {
+				continue
+			}
+
+			event := pointer.SafeDeref(e.Event)
+			if len(eventTypes) > 0 && !slices.ContainsFunc(eventTypes, func(t string) bool {
+				return strings.EqualFold(t, event)
+			}) {
+				continue
+			}
+
+			if message != nil && !message.MatchString(e.Message) {
+				continue
+			}
+
+			var hostname string
+			if m.Allocation != nil {
+				hostname = pointer.SafeDeref(m.Allocation.Hostname)
+			}
+
+			events = append(events, &tableprinters.MachineEvent{
+				MachineID: pointer.SafeDeref(m.ID),
+				Partition: pointer.SafeDeref(pointer.SafeDeref(m.Partition).ID),
+				Rack:      m.Rackid,
+				Hostname:  hostname,
+				Time:      e.Time,
+				Event:     event,
+				Message:   e.Message,
+			})
+		}
+	}
+
+	slices.SortStableFunc(events, func(a, b *tableprinters.MachineEvent) int {
+		if n := time.Time(a.Time).Compare(time.Time(b.Time)); n != 0 {
+			return n
+		}
+		return strings.Compare(a.MachineID, b.MachineID)
+	})
+
+	return c.listPrinter.Print(events)
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-go/test/client"
+	"github.com/metal-stack/metal-lib/pkg/testcommon"
+	"github.com/metal-stack/metalctl/cmd/tableprinters"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_MachineEventsCmd(t *testing.T) {
+	eventsMachine1 := mustJsonDeepCopy(t, machine1)
+	eventsMachine1.Events.Log = []*models.V1MachineProvisioningEvent{
+		{
+			Event:   new("Phoned Home"),
+			Message: "phoning home",
+			Time:    strfmt.DateTime(testTime.Add(-30 * time.Minute)),
+		},
+		{
+			Event:   new("Installing"),
+			Message: "installing image\nwith details",
+			Time:    strfmt.DateTime(testTime.Add(-40 * time.Minute)),
+		},
+	}
+
+	eventsMachine2 := mustJsonDeepCopy(t, machine2)
+	eventsMachine2.Rackid = "rack-2"
+	eventsMachine2.Events.Log = []*models.V1MachineProvisioningEvent{
+		{
+			Event:   new("Waiting"),
+			Message: "waiting for allocation",
+			Time:    strfmt.DateTime(testTime.Add(-35 * time.Minute)),
+		},
+		{
+			Event:   new("Crashed"),
+			Message: "kernel panic",
+			Time:    strfmt.DateTime(testTime.Add(-3 * time.Hour)),
+		},
+	}
+
+	findMachines := func(partition string) *client.MetalMockFns {
+		return &client.MetalMockFns{
+			Machine: func(mock *mock.Mock) {
+				mock.On("FindMachines", testcommon.MatchIgnoreContext(t, machine.NewFindMachinesParams().WithBody(&models.V1MachineFindRequest{
+					NicsMacAddresses:           nil,
+					NetworkDestinationPrefixes: []string{},
+					NetworkIps:                 []string{},
+					NetworkIds:                 []string{},
+					PartitionID:                partition,
+					Tags:                       []string{},
+				})), nil).Return(&machine.FindMachinesOK{
+					Payload: []*models.V1MachineResponse{
+						eventsMachine1,
+						eventsMachine2,
+					},
+				}, nil)
+			},
+		}
+	}
+
+	tests := []*test[[]*tableprinters.MachineEvent]{
+		{
+			name: "timeline",
+			cmd: func(want []*tableprinters.MachineEvent) []string {
+				return []string{"machine", "events", "--partition", "1", "--since", "2h"}
+			},
+			mocks: findMachines("1"),
+			want: []*tableprinters.MachineEvent{
+				{
+					MachineID: "1",
+					Partition: "1",
+					Rack:      "rack-1",
+					Hostname:  "machine-hostname-1",
+					Time:      strfmt.DateTime(testTime.Add(-40 * time.Minute)),
+					Event:     "Installing",
+					Message:   "installing image\nwith details",
+				},
+				{
+					MachineID: "2",
+					Partition: "1",
+					Rack:      "rack-2",
+					Time:      strfmt.DateTime(testTime.Add(-35 * time.Minute)),
+					Event:     "Waiting",
+					Message:   "waiting for allocation",
+				},
+				{
+					MachineID: "1",
+					Partition: "1",
+					Rack:      "rack-1",
+					Hostname:  "machine-hostname-1",
+					Time:      strfmt.DateTime(testTime.Add(-30 * time.Minute)),
+					Event:     "Phoned Home",
+					Message:   "phoning home",
+				},
+			},
+			wantTable: new(`
+TIME                  MACHINE  RACK    EVENT        MESSAGE
+2022-05-19T00:22:03Z  1        rack-1  Installing   installing image ...
+2022-05-19T00:27:03Z  2        rack-2  Waiting      waiting for allocation
+2022-05-19T00:32:03Z  1        rack-1  Phoned Home  phoning home
+`),
+			wantWideTable: new(`
+TIME                  MACHINE  PARTITION  RACK    HOSTNAME            EVENT        MESSAGE
+2022-05-19T00:22:03Z  1        1          rack-1  machine-hostname-1  Installing   installing image
+with details
+2022-05-19T00:27:03Z  2        1          rack-2                      Waiting      waiting for allocation
+2022-05-19T00:32:03Z  1        1          rack-1  machine-hostname-1  Phoned Home  phoning home
+`),
+		},
+		{
+			name: "filter by event and message",
+			cmd: func(want []*tableprinters.MachineEvent) []string {
+				return []string{"machine", "events", "--since", "0", "--event", "crashed,waiting", "--message", "^(kernel|wait)"}
+			},
+			mocks: findMachines(""),
+			want: []*tableprinters.MachineEvent{
+				{
+					MachineID: "2",
+					Partition: "1",
+					Rack:      "rack-2",
+					Time:      strfmt.DateTime(testTime.Add(-3 * time.Hour)),
+					Event:     "Crashed",
+					Message:   "kernel panic",
+				},
+				{
+					MachineID: "2",
+					Partition: "1",
+					Rack:      "rack-2",
+					Time:      strfmt.DateTime(testTime.Add(-35 * time.Minute)),
+					Event:     "Waiting",
+					Message:   "waiting for allocation",
+				},
+			},
+			wantTable: new(`
+TIME                  MACHINE  RACK    EVENT    MESSAGE
+2022-05-18T22:02:03Z  2        rack-2  Crashed  kernel panic
+2022-05-19T00:27:03Z  2        rack-2  Waiting  waiting for allocation
+`),
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+	"github.com/spf13/viper"
+)
+
+const (
+	// machineLogsTerminalEvent ends following the provisioning logs
+	machineLogsTerminalEvent = "Phoned Home"
+	// selEvent is the event name of system event log entries in the provisioning logs
+	selEvent = "SEL"
+	// selEntriesPerPoll limits the amount of system event log entries fetched from the bmc on every poll
+	selEntriesPerPoll = 50
+)
+
+// selTimeLayouts are the timestamp formats of the system event log printed by different ipmitool versions
+var selTimeLayouts = []string{
+	"01/02/2006 15:04:05",
+	"01/02/2006 03:04:05 PM",
+	"01/02/2006 03:04:05 PM MST",
+}
+
+// logEntry is a provisioning event or system event log entry, which is identified by its key for de-duplication
+type logEntry struct {
+	key   string
+	event *models.V1MachineProvisioningEvent
+}
+
+// machineLogsFollow polls the provisioning events of the machine and prints the ones, which were not printed before, in
+// chronological order. It ends when the machine has phoned home or is in a crash loop and prints a recent last error then.
+func (c *machineCmd) machineLogsFollow(id string) error {
+	interval := viper.GetDuration("interval")
+	if interval <= 0 {
+		return &usageError{err: fmt.Errorf("interval must be greater than zero")}
+	}
+
+	var (
+		sel         *ipmitool
+		selLocation *time.Location
+	)
+	if viper.GetBool("sel") {
+		var err error
+		selLocation, err = time.LoadLocation(viper.GetString("sel-timezone"))
+		if err != nil {
+			return &usageError{err: fmt.Errorf("invalid sel-timezone: %w", err)}
+		}
+		sel, err = c.newIpmitool(id)
+		if err != nil {
+			return err
+		}
+	}
+
+	var (
+		seen    = map[string]bool{}
+		printer = c.listPrinter
+		printed bool
+	)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		m, err := c.Get(id)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		entries := provisioningLogEntries(m)
+
+		if sel != nil {
+			selEntries, err := sel.selEntries(c.ctx, selLocation)
+			if errors.Is(err, context.Canceled) {
+				return nil
+			}
+			if err != nil {
+				// the bmc is not always reachable, the provisioning events are still worth following
+				c.log.Warn("unable to read system event log", "error", err)
+			}
+			entries = append(entries, selEntries...)
+		}
+
+		slices.SortStableFunc(entries, func(a, b *logEntry) int {
+			return time.Time(a.event.Time).Compare(time.Time(b.event.Time))
+		})
+
+		var events []*models.V1MachineProvisioningEvent
+		for _, entry := range entries {
+			if seen[entry.key] {
+				continue
+			}
+			seen[entry.key] = true
+			events = append(events, entry.event)
+		}
+
+		if len(events) > 0 {
+			err = printer.Print(events)
+			if err != nil {
+				return err
+			}
+
+			if !printed {
+				// the following listings continue the table of the first one
+				printed = true
+				printer = newPrinter(c.out, c.tableColumns, true)
+			}
+		}
+
+		// the newest provisioning event decides whether following ends, which is already the case on the first poll
+		// for a machine that has phoned home or is in a crash loop
+		if pointer.SafeDeref(pointer.SafeDeref(m.Events).CrashLoop) {
+			err = c.printLastErrorEvent(m)
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("machine %s is in a provisioning crash loop", id)
+		}
+		if last := pointer.FirstOrZero(pointer.SafeDeref(m.Events).Log); last != nil && pointer.SafeDeref(last.Event) == machineLogsTerminalEvent {
+			return c.printLastErrorEvent(m)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// provisioningLogEntries returns the provisioning events of the machine, which are de-duplicated by their timestamp
+func provisioningLogEntries(m *models.V1MachineResponse) []*logEntry {
+	var entries []*logEntry
+	for _, event := range pointer.SafeDeref(m.Events).Log {
+		entries = append(entries, &logEntry{
+			key:   provisioningKey(event),
+			event: event,
+		})
+	}
+	return entries
+}
+
+func provisioningKey(event *models.V1MachineProvisioningEvent) string {
+	return time.Time(event.Time).UTC().Format(time.RFC3339Nano)
+}
+
+// ipmitool runs ipmitool against the bmc of a machine
+type ipmitool struct {
+	path     string
+	args     []string
+	password string
+}
+
+func (c *machineCmd) newIpmitool(id string) (*ipmitool, error) {
+	path, err := exec.LookPath("ipmitool")
+	if err != nil {
+		return nil, fmt.Errorf("unable to locate ipmitool in path")
+	}
+
+	resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(id).WithContext(c.ctx), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ipmi := resp.Payload.Ipmi
+	intf := "lanplus"
+	if *ipmi.Interface != "" {
+		intf = *ipmi.Interface
+	}
+	// -I lanplus  -H 192.168.2.19 -U ADMIN -P ADMIN sol activate
+	hostAndPort := strings.Split(*ipmi.Address, ":")
+	if len(hostAndPort) < 2 {
+		hostAndPort = append(hostAndPort, "623")
+	}
+	usr := *ipmi.User
+	ipmiuser := viper.GetString("ipmiuser")
+	if ipmiuser != "" {
+		usr = ipmiuser
+	} else if usr == "" {
+		c.log.Warn("no ipmi user stored, please specify with --ipmiuser")
+	}
+
+	password := *ipmi.Password
+	ipmipassword := viper.GetString("ipmipassword")
+	if ipmipassword != "" {
+		password = ipmipassword
+	} else if password == "" {
+		c.log.Warn("no ipmi password stored, please specify with --ipmipassword")
+	}
+
+	return &ipmitool{
+		path:     path,
+		args:     []string{"-I", intf, "-H", hostAndPort[0], "-p", hostAndPort[1], "-U", usr, "-E"},
+		password: password,
+	}, nil
+}
+
+// command returns the ipmitool command with the given arguments, the password is passed through the environment
+func (i *ipmitool) command(ctx context.Context, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, i.path, append(slices.Clone(i.args), args...)...)
+	cmd.Env = append(os.Environ(), "IPMITOOL_PASSWORD="+i.password)
+	return cmd
+}
+
+// selEntries returns the latest entries of the system event log, timestamps without a zone are interpreted in the given location
+func (i *ipmitool) selEntries(ctx context.Context, loc *time.Location) ([]*logEntry, error) {
+	out, err := i.command(ctx, "sel", "elist", "last", strconv.Itoa(selEntriesPerPoll)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to list system event log: %w", err)
+	}
+
+	return parseSELEntries(string(out), loc), nil
+}
+
+// parseSELEntries parses the output of ipmitool sel elist, entries without a valid timestamp are skipped. Timestamps
+// without a zone are interpreted in the given location.
+//
+//	1 | 10/17/2026 | 10:00:01 | Power Supply #0x51 | Power Supply AC lost | Asserted
+func parseSELEntries(out string, loc *time.Location) []*logEntry {
+	var entries []*logEntry
+
+	for line := range strings.Lines(out) {
+		fields := strings.Split(line, "|")
+		if len(fields) < 4 {
+			continue
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		var (
+			timestamp = fields[1] + " " + fields[2]
+			t         time.Time
+			err       error
+		)
+		for _, layout := range selTimeLayouts {
+			t, err = time.ParseInLocation(layout, timestamp, loc)
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			continue
+		}
+
+		entries = append(entries, &logEntry{
+			key: "sel/" + fields[0],
+			event: &models.V1MachineProvisioningEvent{
+				Event:   new(selEvent),
+				Message: strings.Join(fields[3:], " | "),
+				Time:    strfmt.DateTime(t),
+			},
+		})
+	}
+
+	return entries
+}
@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-go/test/client"
+	"github.com/metal-stack/metal-lib/pkg/testcommon"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_MachineLogsCmd_Follow(t *testing.T) {
+	var (
+		waiting = &models.V1MachineProvisioningEvent{
+			Event:   new("Waiting"),
+			Message: "waiting for allocation",
+			Time:    strfmt.DateTime(testTime.Add(-2 * time.Minute)),
+		}
+		installing = &models.V1MachineProvisioningEvent{
+			Event:   new("Installing"),
+			Message: "installing image",
+			Time:    strfmt.DateTime(testTime.Add(-1 * time.Minute)),
+		}
+		phonedHome = &models.V1MachineProvisioningEvent{
+			Event:   new("Phoned Home"),
+			Message: "phoning home",
+			Time:    strfmt.DateTime(testTime),
+		}
+	)
+
+	withEvents := func(events ...*models.V1MachineProvisioningEvent) *models.V1MachineResponse {
+		m := mustJsonDeepCopy(t, machine1)
+		m.Events.Log = events
+		return m
+	}
+
+	tests := []*test[[]*models.V1MachineProvisioningEvent]{
+		{
+			name: "follow until phoned home",
+			cmd: func(want []*models.V1MachineProvisioningEvent) []string {
+				args := []string{"machine", "logs", "1", "--follow", "--interval", "10ms"}
+				assertExhaustiveArgs(t, args, "last-event-error-threshold", "sel", "sel-timezone", "ipmiuser", "ipmipassword")
+				return args
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID("1")), nil).Return(&machine.FindMachineOK{
+						Payload: withEvents(installing, waiting),
+					}, nil).Once()
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID("1")), nil).Return(&machine.FindMachineOK{
+						Payload: withEvents(installing, waiting),
+					}, nil).Once()
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID("1")), nil).Return(&machine.FindMachineOK{
+						Payload: withEvents(phonedHome, installing, waiting),
+					}, nil)
+				},
+			},
+			wantTable: new(`
+TIME                           EVENT       MESSAGE
+Thu, 19 May 2022 01:00:03 UTC  Waiting     waiting for allocation
+Thu, 19 May 2022 01:01:03 UTC  Installing  installing image
+Thu, 19 May 2022 01:02:03 UTC  Phoned Home  phoning home
+`),
+		},
+		{
+			name: "already phoned home with recent last error",
+			cmd: func(want []*models.V1MachineProvisioningEvent) []string {
+				return []string{"machine", "logs", "1", "--follow", "--interval", "10ms"}
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					m := withEvents(phonedHome, installing)
+					m.Events.LastErrorEvent = &models.V1MachineProvisioningEvent{
+						Event:   new("Crashed"),
+						Message: "crash",
+						Time:    strfmt.DateTime(testTime.Add(-30 * time.Minute)),
+					}
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID("1")), nil).Return(&machine.FindMachineOK{
+						Payload: m,
+					}, nil).Once()
+				},
+			},
+			wantTable: new(`
+TIME                           EVENT        MESSAGE
+Thu, 19 May 2022 01:01:03 UTC  Installing   installing image
+Thu, 19 May 2022 01:02:03 UTC  Phoned Home  phoning home
+
+Recent last error (30m0s ago):
+
+TIME                           EVENT    MESSAGE
+Thu, 19 May 2022 00:32:03 UTC  Crashed  crash
+`),
+		},
+		{
+			name: "already in a crash loop",
+			cmd: func(want []*models.V1MachineProvisioningEvent) []string {
+				return []string{"machine", "logs", "1", "--follow", "--interval", "10ms"}
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					m := withEvents(installing, waiting)
+					m.Events.CrashLoop = new(true)
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID("1")), nil).Return(&machine.FindMachineOK{
+						Payload: m,
+					}, nil).Once()
+				},
+			},
+			wantErr: errors.New("machine 1 is in a provisioning crash loop"),
+		},
+		{
+			name: "invalid sel time zone",
+			cmd: func(want []*models.V1MachineProvisioningEvent) []string {
+				return []string{"machine", "logs", "1", "--follow", "--sel", "--sel-timezone", "Mars/Olympus"}
+			},
+			wantErr: &usageError{err: errors.New("invalid sel-timezone: unknown time zone Mars/Olympus")},
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
+
+func Test_parseSELEntries(t *testing.T) {
+	out := `   1 | 10/17/2026 | 10:00:01 | Power Supply #0x51 | Power Supply AC lost | Asserted
+   2 | 10/17/2026 | 10:05:12 AM | System Boot Initiated #0xe0 | Initiated by power up | Asserted
+   3 | Pre-Init  |  Time-stamp not available | Memory #0x02 | Correctable ECC | Asserted
+SEL has no entries
+`
+
+	// the bmc reports its local time
+	loc := time.FixedZone("CEST", 2*60*60)
+
+	got := parseSELEntries(out, loc)
+
+	want := []*logEntry{
+		{
+			key: "sel/1",
+			event: &models.V1MachineProvisioningEvent{
+				Event:   new(selEvent),
+				Message: "Power Supply #0x51 | Power Supply AC lost | Asserted",
+				Time:    strfmt.DateTime(time.Date(2026, time.October, 17, 8, 0, 1, 0, time.UTC)),
+			},
+		},
+		{
+			key: "sel/2",
+			event: &models.V1MachineProvisioningEvent{
+				Event:   new(selEvent),
+				Message: "System Boot Initiated #0xe0 | Initiated by power up | Asserted",
+				Time:    strfmt.DateTime(time.Date(2026, time.October, 17, 8, 5, 12, 0, time.UTC)),
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(logEntry{}), testcommon.StrFmtDateComparer()); diff != "" {
+		t.Errorf("diff (+got -want):\n %s", diff)
+	}
+}
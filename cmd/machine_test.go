@@ -290,6 +290,49 @@ ID  LAST EVENT   WHEN  AGE  DESCRIPTION           NAME       HOSTNAME
 |----|--|-------------|------|-----|--------------------|-----------|------|-------------|-----------|--------|
 | 2  |  | Waiting     | 1m   |     |                    |           | 1    |             | 1         | rack-1 |
 | 1  |  | Phoned Home | 7d   | 14d | machine-hostname-1 | project-1 | 1    | debian-name | 1         | rack-1 |
+`),
+			wantCSV: new(`
+ID,Last Event,When,Age,Description,Name,Hostname,Project,Ips,Size,Image,Partition,Rack,Started,Tags,Lock/Reserve
+2,Waiting,1m,,,,,,,1,,1,rack-1,,b,
+1,Phoned Home,7d,14d,machine allocation 1,machine-1,machine-hostname-1,project-1,1.1.1.1,1,debian-name,1,rack-1,2022-05-05T01:02:03Z,a,
+`),
+		},
+		{
+			name: "list with bmc and fru columns",
+			cmd: func(want []*models.V1MachineResponse) []string {
+				return []string{"machine", "list", "--columns", "id,hostname,bmc-address,product-serial,chassis-serial"}
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					findRequest := &models.V1MachineFindRequest{
+						NicsMacAddresses:           nil,
+						NetworkDestinationPrefixes: []string{},
+						NetworkIps:                 []string{},
+						NetworkIds:                 []string{},
+						Tags:                       []string{},
+					}
+					mock.On("FindMachines", testcommon.MatchIgnoreContext(t, machine.NewFindMachinesParams().WithBody(findRequest)), nil).Return(&machine.FindMachinesOK{
+						Payload: []*models.V1MachineResponse{
+							machine1,
+							machine2,
+						},
+					}, nil)
+					mock.On("FindIPMIMachines", testcommon.MatchIgnoreContext(t, machine.NewFindIPMIMachinesParams().WithBody(findRequest)), nil).Return(&machine.FindIPMIMachinesOK{
+						Payload: []*models.V1MachineIPMIResponse{
+							ipmiMachine1,
+						},
+					}, nil)
+				},
+			},
+			wantTable: new(`
+ID  HOSTNAME            BMC ADDRESS  PRODUCT SERIAL  CHASSIS SERIAL
+2
+1   machine-hostname-1  1.2.3.4      product123      chassis123
+`),
+			wantCSV: new(`
+ID,Hostname,BMC Address,Product Serial,Chassis Serial
+2,,,,
+1,machine-hostname-1,1.2.3.4,product123,chassis123
 `),
 		},
 		{
@@ -440,7 +483,7 @@ func Test_MachineCmd_SingleResult(t *testing.T) {
 					"--dnsservers", strings.Join(dnsServers, ","),
 					"--ntpservers", strings.Join(ntpservers, ","),
 				}
-				assertExhaustiveArgs(t, args, commonExcludedFileArgs()...)
+				assertExhaustiveArgs(t, args, append(commonExcludedFileArgs(), "wait", "wait-timeout")...)
 				return args
 			},
 			mocks: &client.MetalMockFns{
@@ -565,6 +608,10 @@ ID  LAST EVENT   STATUS  POWER                       IP       MAC      BOARD PAR
 | ID |  | POWER | IP      | MAC     | BOARD PART NUMBER | BIOS | BMC | SIZE | PARTITION | RACK   | UPDATED |
 |----|--|-------|---------|---------|-------------------|------|-----|------|-----------|--------|---------|
 | 1  |  | ⏻ 16W | 1.2.3.4 | 1.2.3.4 | part123           | 2.0  | 1.1 | 1    | 1         | rack-1 | 5s ago  |
+`),
+			wantCSV: new(`
+ID,Last Event,Status,Power,IP,Mac,Board Part Number,Chassis Serial,Product Serial,Bios Version,BMC Version,Size,Partition,Rack,Updated
+1,Phoned Home,,ON Power Supply NOT-OK 16W,1.2.3.4,1.2.3.4,part123,chassis123,product123,2.0,1.1,1,1,rack-1,5s ago
 `),
 		},
 	}
@@ -0,0 +1,292 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-lib/pkg/genericcli"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// kinds of conditions a machine can be waited for
+const (
+	machineConditionEvent      = "event"
+	machineConditionLiveliness = "liveliness"
+	machineConditionAllocated  = "allocated"
+	machineConditionState      = "state"
+)
+
+// machineWaitBackoff is the backoff between two polls of the machine while waiting
+var machineWaitBackoff = api.RetryPolicy{
+	InitialBackoff: 2 * time.Second,
+	MaxBackoff:     30 * time.Second,
+}
+
+// machineCondition is a condition given with --for, e.g. event=Phoned Home
+type machineCondition struct {
+	kind  string
+	value string
+}
+
+func parseMachineCondition(condition string) (*machineCondition, error) {
+	kind, value, hasValue := strings.Cut(condition, "=")
+
+	switch kind {
+	case machineConditionEvent, machineConditionLiveliness:
+		if value == "" {
+			return nil, fmt.Errorf("condition %q requires a value, e.g. %s=<value>", condition, kind)
+		}
+	case machineConditionState:
+		// an empty state waits for the removal of a lock or reservation
+	case machineConditionAllocated:
+		if hasValue {
+			return nil, fmt.Errorf("condition %q does not take a value", condition)
+		}
+	default:
+		return nil, fmt.Errorf("unknown condition %q, must be one of event=<event>|liveliness=<liveliness>|allocated|state=<state>", condition)
+	}
+
+	return &machineCondition{kind: kind, value: value}, nil
+}
+
+func (mc *machineCondition) String() string {
+	if mc.kind == machineConditionAllocated {
+		return mc.kind
+	}
+	return mc.kind + "=" + mc.value
+}
+
+// met returns true if the machine fulfills the condition, events which happened before since are not considered
+func (mc *machineCondition) met(m *models.V1MachineResponse, since time.Time) bool {
+	switch mc.kind {
+	case machineConditionEvent:
+		log := pointer.SafeDeref(m.Events).Log
+		if len(log) == 0 || time.Time(log[0].Time).Before(since) {
+			return false
+		}
+		return strings.EqualFold(pointer.SafeDeref(log[0].Event), mc.value)
+	case machineConditionLiveliness:
+		return strings.EqualFold(pointer.SafeDeref(m.Liveliness), mc.value)
+	case machineConditionAllocated:
+		return m.Allocation != nil && pointer.SafeDeref(m.Allocation.Succeeded)
+	case machineConditionState:
+		return strings.EqualFold(pointer.SafeDeref(pointer.SafeDeref(m.State).Value), mc.value)
+	default:
+		return false
+	}
+}
+
+// machineWait describes what is waited for by machine wait and the --wait flag of machine create and reinstall
+type machineWait struct {
+	conditions []*machineCondition
+	// since ignores provisioning events and errors, which happened before
+	since   time.Time
+	timeout time.Duration
+}
+
+func newMachineWaitCmd(c *machineCmd) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wait <machine ID>",
+		Short: "wait until a machine reaches the given conditions",
+		Long: `polls the machine with backoff until all conditions given with --for are met and prints the machine afterwards.
+Waiting fails immediately when the machine reports a provisioning error or a crash loop and with exit code 10 on timeout.
+
+The --timeout flag of this command is the maximum duration to wait. It shadows the global --timeout for every request
+against the metal-api, which is still taken from the timeout of the config file or the METALCTL_TIMEOUT environment variable.
+
+Conditions:
+
+event=<event>            the most recent provisioning event, e.g. event=Phoned Home
+liveliness=<liveliness>  the liveliness of the machine, e.g. liveliness=Alive
+allocated                the machine is allocated and its installation succeeded
+state=<state>            the state of the machine, e.g. state=LOCKED, an empty state waits until lock or reservation are removed
+`,
+		Example: `metalctl machine power cycle <machine ID>
+metalctl machine wait <machine ID> --for "event=Phoned Home" --since 1m --timeout 30m`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return c.machineWait(cmd, args)
+		},
+		ValidArgsFunction: c.comp.MachineListCompletion,
+		// the local --timeout must not override the request timeout bound to viper
+		Annotations: map[string]string{unboundFlagsAnnotation: "true"},
+	}
+
+	cmd.Flags().StringSlice("for", nil, "the conditions to wait for, all of them must be met. [required]")
+	cmd.Flags().Duration("timeout", 30*time.Minute, "the maximum duration to wait for the conditions.")
+	cmd.Flags().Duration("since", 0, "only consider provisioning events of this recent duration, e.g. to wait for a new event after power cycle. Without it, all events are considered and only errors occurring while waiting fail.")
+
+	genericcli.Must(cmd.MarkFlagRequired("for"))
+	genericcli.Must(cmd.RegisterFlagCompletionFunc("for", cobra.FixedCompletions([]string{
+		"event=Phoned Home",
+		"event=Waiting",
+		"liveliness=Alive",
+		"allocated",
+		"state=LOCKED",
+		"state=RESERVED",
+	}, cobra.ShellCompDirectiveNoFileComp)))
+
+	return cmd
+}
+
+// addMachineWaitFlags adds --wait to commands, which provision a machine
+func addMachineWaitFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, "wait until the machine is provisioned and has phoned home, fails on provisioning errors.")
+	cmd.Flags().Duration("wait-timeout", 30*time.Minute, "the maximum duration to wait with --wait.")
+}
+
+func (c *machineCmd) machineWait(cmd *cobra.Command, args []string) error {
+	id, err := genericcli.GetExactlyOneArg(args)
+	if err != nil {
+		return err
+	}
+
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return err
+	}
+	conditions, err := cmd.Flags().GetStringSlice("for")
+	if err != nil {
+		return err
+	}
+	since, err := cmd.Flags().GetDuration("since")
+	if err != nil {
+		return err
+	}
+
+	wait := &machineWait{
+		timeout: timeout,
+	}
+
+	for _, condition := range conditions {
+		mc, err := parseMachineCondition(condition)
+		if err != nil {
+			return &usageError{err: err}
+		}
+		wait.conditions = append(wait.conditions, mc)
+	}
+
+	if since > 0 {
+		wait.since = time.Now().Add(-since)
+	}
+
+	m, err := c.waitForMachine(id, wait)
+	if err != nil {
+		return err
+	}
+
+	return c.listPrinter.Print(m)
+}
+
+// provisionWait is used for the --wait flag of machine create and reinstall, the machine has to phone home after the given time
+func provisionWait(since time.Time) *machineWait {
+	return &machineWait{
+		conditions: []*machineCondition{
+			{kind: machineConditionAllocated},
+			{kind: machineConditionEvent, value: "Phoned Home"},
+		},
+		since:   since,
+		timeout: viper.GetDuration("wait-timeout"),
+	}
+}
+
+// waitForMachine polls the machine until all conditions are met. It fails as soon as the machine
+// reports a provisioning error or a crash loop, which is not older than the start of the wait.
+func (c *machineCmd) waitForMachine(id string, wait *machineWait) (*models.V1MachineResponse, error) {
+	if wait.timeout <= 0 {
+		return nil, &usageError{err: fmt.Errorf("timeout must be greater than zero")}
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, wait.timeout)
+	defer cancel()
+
+	errorsSince := wait.since
+	if errorsSince.IsZero() {
+		errorsSince = time.Now()
+	}
+
+	var lastEvent string
+
+	for poll := 0; ; poll++ {
+		resp, err := c.client.Machine().FindMachine(machine.NewFindMachineParams().WithID(id).WithContext(ctx), nil)
+		switch {
+		case ctx.Err() != nil:
+			// the wait timed out or was interrupted, which is handled below
+		case errors.Is(err, context.DeadlineExceeded):
+			// only the request timed out, the machine is polled again
+			c.log.Debug("polling machine timed out", "id", id, "error", err)
+		case err != nil:
+			return nil, err
+		default:
+			m := resp.Payload
+
+			if event := pointer.SafeDeref(pointer.SafeDeref(pointer.FirstOrZero(pointer.SafeDeref(m.Events).Log)).Event); event != lastEvent {
+				c.log.Info("waiting for machine", "id", id, "event", event, "for", fmt.Sprint(wait.conditions))
+				lastEvent = event
+			}
+
+			if err := machineProvisioningError(m, errorsSince); err != nil {
+				return nil, err
+			}
+
+			met := true
+			for _, mc := range wait.conditions {
+				met = met && mc.met(m, wait.since)
+			}
+			if met {
+				return m, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, wait.timeoutError(id, lastEvent)
+			}
+			return nil, ctx.Err()
+		case <-time.After(machineWaitBackoff.Backoff(poll)):
+		}
+	}
+}
+
+func (w *machineWait) timeoutError(id, lastEvent string) error {
+	return &waitTimeoutError{
+		msg: fmt.Sprintf("timeout after %s waiting for machine %s to reach %s, last event was %q", w.timeout, id, w.conditions, lastEvent),
+	}
+}
+
+// waitTimeoutError is returned when the conditions are not met in time, other than request timeouts
+// it can not be solved by raising the request timeout. It results in the timeout exit code.
+type waitTimeoutError struct {
+	msg string
+}
+
+func (e *waitTimeoutError) Error() string {
+	return e.msg
+}
+
+func (e *waitTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// machineProvisioningError returns an error if the machine reported a provisioning error or crash loop after the given time
+func machineProvisioningError(m *models.V1MachineResponse, since time.Time) error {
+	events := pointer.SafeDeref(m.Events)
+
+	if errorEvent := events.LastErrorEvent; errorEvent != nil && !time.Time(errorEvent.Time).Before(since) {
+		return fmt.Errorf("machine %s reported a provisioning error %q: %s", pointer.SafeDeref(m.ID), pointer.SafeDeref(errorEvent.Event), errorEvent.Message)
+	}
+
+	if pointer.SafeDeref(events.CrashLoop) && !time.Time(events.LastEventTime).Before(since) {
+		return fmt.Errorf("machine %s is in a provisioning crash loop", pointer.SafeDeref(m.ID))
+	}
+
+	return nil
+}
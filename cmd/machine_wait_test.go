@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-go/test/client"
+	"github.com/metal-stack/metal-lib/pkg/testcommon"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// provisionedMachine returns machine1 with provisioning events at the mocked current time
+func provisionedMachine(t *testing.T) *models.V1MachineResponse {
+	m := mustJsonDeepCopy(t, machine1)
+	m.Events.LastEventTime = strfmt.DateTime(testTime)
+	m.Events.Log[0].Time = strfmt.DateTime(testTime)
+	return m
+}
+
+func Test_MachineWaitCmd_SingleResult(t *testing.T) {
+	tests := []*test[*models.V1MachineResponse]{
+		{
+			name: "wait",
+			cmd: func(want *models.V1MachineResponse) []string {
+				args := []string{"machine", "wait", *want.ID, "--for", "allocated,event=Phoned Home,liveliness=Alive", "--since", "192h", "--timeout", "1m"}
+				assertExhaustiveArgs(t, args)
+				return args
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					// the wait timeout does not change the request timeout
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID(*machine1.ID).WithTimeout(30*time.Second)), nil).Return(&machine.FindMachineOK{
+						Payload: machine1,
+					}, nil)
+				},
+			},
+			want: machine1,
+			wantTable: new(`
+		ID    LAST EVENT   WHEN  AGE  HOSTNAME            PROJECT    SIZE  IMAGE        PARTITION  RACK
+		1     Phoned Home  7d    14d  machine-hostname-1  project-1  1     debian-name  1          rack-1
+		`),
+		},
+		{
+			name: "reinstall with wait",
+			cmd: func(want *models.V1MachineResponse) []string {
+				args := []string{"machine", "reinstall", *want.ID, "--image", *want.Allocation.Image.ID, "--description", "reinstall", "--wait", "--wait-timeout", "1m"}
+				assertExhaustiveArgs(t, args)
+				return args
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					mock.On("ReinstallMachine", testcommon.MatchIgnoreContext(t, machine.NewReinstallMachineParams().WithID(*machine1.ID).WithTimeout(30*time.Second).WithBody(&models.V1MachineReinstallRequest{
+						ID:          machine1.ID,
+						Description: "reinstall",
+						Imageid:     machine1.Allocation.Image.ID,
+					})), nil).Return(&machine.ReinstallMachineOK{
+						Payload: machine1,
+					}, nil)
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID(*machine1.ID).WithTimeout(30*time.Second)), nil).Return(&machine.FindMachineOK{
+						Payload: provisionedMachine(t),
+					}, nil)
+				},
+			},
+			want: provisionedMachine(t),
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
+
+func Test_MachineWaitCmd_RequestTimeout(t *testing.T) {
+	backoff := machineWaitBackoff
+	machineWaitBackoff = api.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	t.Cleanup(func() { machineWaitBackoff = backoff })
+
+	tests := []*test[*models.V1MachineResponse]{
+		{
+			name: "timed out request is polled again",
+			cmd: func(want *models.V1MachineResponse) []string {
+				return []string{"machine", "wait", *want.ID, "--for", "allocated", "--timeout", "1m"}
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					params := testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID(*machine1.ID).WithTimeout(30*time.Second))
+					mock.On("FindMachine", params, nil).Return(nil, fmt.Errorf("request failed: %w", context.DeadlineExceeded)).Once()
+					mock.On("FindMachine", params, nil).Return(&machine.FindMachineOK{
+						Payload: machine1,
+					}, nil)
+				},
+			},
+			want: machine1,
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
+
+func Test_MachineWaitCmd_MultiResult(t *testing.T) {
+	tests := []*test[[]*models.V1MachineResponse]{
+		{
+			name: "create from file with wait",
+			cmd: func(want []*models.V1MachineResponse) []string {
+				return append(appendFromFileCommonArgs("machine", "create"), "--wait")
+			},
+			fsMocks: func(fs afero.Fs, want []*models.V1MachineResponse) {
+				require.NoError(t, afero.WriteFile(fs, "/file.yaml", mustMarshalToMultiYAML(t, want), 0755))
+			},
+			mocks: &client.MetalMockFns{
+				Machine: func(mock *mock.Mock) {
+					mock.On("AllocateMachine", testcommon.MatchIgnoreContext(t, machine.NewAllocateMachineParams().WithTimeout(30*time.Second).WithBody(machineResponseToCreate(provisionedMachine(t)))), nil).Return(&machine.AllocateMachineOK{
+						Payload: machine1,
+					}, nil)
+					mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID(*machine1.ID).WithTimeout(30*time.Second)), nil).Return(&machine.FindMachineOK{
+						Payload: provisionedMachine(t),
+					}, nil)
+				},
+			},
+			want: []*models.V1MachineResponse{
+				provisionedMachine(t),
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
+
+func Test_MachineWaitCmd_Errors(t *testing.T) {
+	failedMachine := provisionedMachine(t)
+	failedMachine.Events.LastErrorEvent.Time = strfmt.DateTime(testTime)
+
+	crashLoopMachine := provisionedMachine(t)
+	crashLoopMachine.Events.CrashLoop = new(true)
+
+	findMachine := func(m *models.V1MachineResponse) *client.MetalMockFns {
+		return &client.MetalMockFns{
+			Machine: func(mock *mock.Mock) {
+				mock.On("FindMachine", testcommon.MatchIgnoreContext(t, machine.NewFindMachineParams().WithID(*m.ID).WithTimeout(30*time.Second)), nil).Return(&machine.FindMachineOK{
+					Payload: m,
+				}, nil)
+			},
+		}
+	}
+
+	tests := []*test[*models.V1MachineResponse]{
+		{
+			name: "timeout",
+			cmd: func(want *models.V1MachineResponse) []string {
+				return []string{"machine", "wait", "1", "--for", "state=LOCKED", "--timeout", "10ms"}
+			},
+			mocks:   findMachine(machine1),
+			wantErr: &waitTimeoutError{msg: `timeout after 10ms waiting for machine 1 to reach [state=LOCKED], last event was "Phoned Home"`},
+		},
+		{
+			name: "provisioning error",
+			cmd: func(want *models.V1MachineResponse) []string {
+				return []string{"machine", "wait", "1", "--for", "event=Phoned Home"}
+			},
+			mocks:   findMachine(failedMachine),
+			wantErr: fmt.Errorf(`machine 1 reported a provisioning error "Crashed": crash`),
+		},
+		{
+			name: "crash loop",
+			cmd: func(want *models.V1MachineResponse) []string {
+				return []string{"machine", "wait", "1", "--for", "liveliness=Alive"}
+			},
+			mocks:   findMachine(crashLoopMachine),
+			wantErr: fmt.Errorf("machine 1 is in a provisioning crash loop"),
+		},
+		{
+			name: "unknown condition",
+			cmd: func(want *models.V1MachineResponse) []string {
+				return []string{"machine", "wait", "1", "--for", "powered"}
+			},
+			wantErr: &usageError{err: errors.New(`unknown condition "powered", must be one of event=<event>|liveliness=<liveliness>|allocated|state=<state>`)},
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
@@ -61,6 +61,7 @@ func newNetworkCmd(c *config) *cobra.Command {
 			genericcli.Must(cmd.RegisterFlagCompletionFunc("partition", c.comp.PartitionListCompletion))
 		},
 		ListCmdMutateFn: func(cmd *cobra.Command) {
+			c.addWatchFlags(cmd)
 			cmd.Flags().String("id", "", "ID to filter [optional]")
 			cmd.Flags().String("name", "", "name to filter [optional]")
 			cmd.Flags().String("partition", "", "partition to filter [optional]")
@@ -178,7 +179,7 @@ func newNetworkCmd(c *config) *cobra.Command {
 }
 
 func (c *networkCmd) Get(id string) (*models.V1NetworkResponse, error) {
-	resp, err := c.client.Network().FindNetwork(network.NewFindNetworkParams().WithID(id), nil)
+	resp, err := c.client.Network().FindNetwork(network.NewFindNetworkParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +201,7 @@ func (c *networkCmd) List() ([]*models.V1NetworkResponse, error) {
 		Destinationprefixes: viper.GetStringSlice("destination-prefixes"),
 		Parentnetworkid:     viper.GetString("parent"),
 		Addressfamily:       viper.GetString("addressfamily"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -209,7 +210,7 @@ func (c *networkCmd) List() ([]*models.V1NetworkResponse, error) {
 }
 
 func (c *networkCmd) Delete(id string) (*models.V1NetworkResponse, error) {
-	resp, err := c.client.Network().DeleteNetwork(network.NewDeleteNetworkParams().WithID(id), nil)
+	resp, err := c.client.Network().DeleteNetwork(network.NewDeleteNetworkParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -218,7 +219,7 @@ func (c *networkCmd) Delete(id string) (*models.V1NetworkResponse, error) {
 }
 
 func (c *networkCmd) Create(rq *models.V1NetworkCreateRequest) (*models.V1NetworkResponse, error) {
-	resp, err := c.client.Network().CreateNetwork(network.NewCreateNetworkParams().WithBody(rq), nil)
+	resp, err := c.client.Network().CreateNetwork(network.NewCreateNetworkParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *network.CreateNetworkConflict
 		if errors.As(err, &r) {
@@ -231,7 +232,7 @@ func (c *networkCmd) Create(rq *models.V1NetworkCreateRequest) (*models.V1Networ
 }
 
 func (c *networkCmd) Update(rq *models.V1NetworkUpdateRequest) (*models.V1NetworkResponse, error) {
-	resp, err := c.client.Network().UpdateNetwork(network.NewUpdateNetworkParams().WithBody(rq).WithForce(new(viper.GetBool(forceFlag))), nil)
+	resp, err := c.client.Network().UpdateNetwork(network.NewUpdateNetworkParams().WithBody(rq).WithForce(new(viper.GetBool(forceFlag))).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -332,7 +333,7 @@ func (c networkChildCRUD) List() ([]*models.V1NetworkResponse, error) {
 }
 
 func (c networkChildCRUD) Delete(id string) (*models.V1NetworkResponse, error) {
-	resp, err := c.client.Network().FreeNetwork(network.NewFreeNetworkParams().WithID(id), nil)
+	resp, err := c.client.Network().FreeNetwork(network.NewFreeNetworkParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -341,7 +342,7 @@ func (c networkChildCRUD) Delete(id string) (*models.V1NetworkResponse, error) {
 }
 
 func (c networkChildCRUD) Create(rq *models.V1NetworkAllocateRequest) (*models.V1NetworkResponse, error) {
-	resp, err := c.client.Network().AllocateNetwork(network.NewAllocateNetworkParams().WithBody(rq), nil)
+	resp, err := c.client.Network().AllocateNetwork(network.NewAllocateNetworkParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *network.AllocateNetworkConflict
 		if errors.As(err, &r) {
@@ -57,6 +57,8 @@ func newPartitionCmd(c *config) *cobra.Command {
 		},
 	}
 
+	c.addWatchFlags(partitionCapacityCmd)
+
 	partitionCapacityCmd.Flags().StringP("id", "", "", "filter on partition id. [optional]")
 	partitionCapacityCmd.Flags().StringP("size", "", "", "filter on size id. [optional]")
 	partitionCapacityCmd.Flags().StringP("project-id", "", "", "consider project-specific counts, e.g. size reservations. [optional]")
@@ -70,7 +72,7 @@ func newPartitionCmd(c *config) *cobra.Command {
 }
 
 func (c *partitionCmd) Get(id string) (*models.V1PartitionResponse, error) {
-	resp, err := c.client.Partition().FindPartition(partition.NewFindPartitionParams().WithID(id), nil)
+	resp, err := c.client.Partition().FindPartition(partition.NewFindPartitionParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +81,7 @@ func (c *partitionCmd) Get(id string) (*models.V1PartitionResponse, error) {
 }
 
 func (c *partitionCmd) List() ([]*models.V1PartitionResponse, error) {
-	resp, err := c.client.Partition().ListPartitions(partition.NewListPartitionsParams(), nil)
+	resp, err := c.client.Partition().ListPartitions(partition.NewListPartitionsParams().WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -88,7 +90,7 @@ func (c *partitionCmd) List() ([]*models.V1PartitionResponse, error) {
 }
 
 func (c *partitionCmd) Delete(id string) (*models.V1PartitionResponse, error) {
-	resp, err := c.client.Partition().DeletePartition(partition.NewDeletePartitionParams().WithID(id), nil)
+	resp, err := c.client.Partition().DeletePartition(partition.NewDeletePartitionParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +99,7 @@ func (c *partitionCmd) Delete(id string) (*models.V1PartitionResponse, error) {
 }
 
 func (c *partitionCmd) Create(rq *models.V1PartitionCreateRequest) (*models.V1PartitionResponse, error) {
-	resp, err := c.client.Partition().CreatePartition(partition.NewCreatePartitionParams().WithBody(rq), nil)
+	resp, err := c.client.Partition().CreatePartition(partition.NewCreatePartitionParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *partition.CreatePartitionConflict
 		if errors.As(err, &r) {
@@ -110,7 +112,7 @@ func (c *partitionCmd) Create(rq *models.V1PartitionCreateRequest) (*models.V1Pa
 }
 
 func (c *partitionCmd) Update(rq *models.V1PartitionUpdateRequest) (*models.V1PartitionResponse, error) {
-	resp, err := c.client.Partition().UpdatePartition(partition.NewUpdatePartitionParams().WithBody(rq), nil)
+	resp, err := c.client.Partition().UpdatePartition(partition.NewUpdatePartitionParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -165,7 +167,7 @@ func (c *partitionCmd) partitionCapacity() error {
 		ID:        viper.GetString("id"),
 		Sizeid:    viper.GetString("size"),
 		Projectid: pointer.PointerOrNil(viper.GetString("project-id")),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
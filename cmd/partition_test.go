@@ -344,7 +344,7 @@ Total              1          3     0            2             |  5      |  2
 			name: "capacity with filters",
 			cmd: func(want []*models.V1PartitionCapacity) []string {
 				args := []string{"partition", "capacity", "--id", "1", "--size", "size-1", "--project-id", "123"}
-				assertExhaustiveArgs(t, args, "sort-by")
+				assertExhaustiveArgs(t, args, "sort-by", "watch", "interval")
 				return args
 			},
 			mocks: &client.MetalMockFns{
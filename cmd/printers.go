@@ -1,19 +1,35 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
+	"regexp"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/metal-stack/metal-lib/pkg/genericcli/printers"
 	"github.com/metal-stack/metalctl/cmd/tableprinters"
 	"github.com/spf13/viper"
+	"k8s.io/client-go/util/jsonpath"
 )
 
-func newPrinterFromCLI(out io.Writer) printers.Printer {
+// newPrinterFromCLI creates the printer for the configured output format, tableColumns are the columns of the current context
+func newPrinterFromCLI(out io.Writer, tableColumns map[string][]string) printers.Printer {
+	return newPrinter(out, tableColumns, viper.GetBool("no-headers"))
+}
+
+// newPrinter creates the printer for the configured output format, which prints table headers unless noHeaders is set
+func newPrinter(out io.Writer, tableColumns map[string][]string, noHeaders bool) printers.Printer {
 	var printer printers.Printer
 
-	switch format := viper.GetString("output-format"); format {
+	// jsonpath and custom-columns take their expression after the format name, e.g. jsonpath={.id}
+	format, expression, _ := strings.Cut(viper.GetString("output-format"), "=")
+
+	switch format {
 	case "yaml":
 		printer = printers.NewYAMLPrinter().WithOut(out)
 	case "json":
@@ -25,17 +41,52 @@ func newPrinterFromCLI(out io.Writer) printers.Printer {
 			ToHeaderAndRows: tp.ToHeaderAndRows,
 			Wide:            format == "wide",
 			Markdown:        format == "markdown",
-			NoHeaders:       viper.GetBool("no-headers"),
+			NoHeaders:       noHeaders,
 			DisableAutoWrap: false,
 		}).WithOut(out)
 
 		tp.SetMarkdown(format == "markdown")
+		tp.SetPlain(viper.GetBool("plain"))
+		tp.SetStatusColumn(viper.GetBool("plain"))
 		tp.SetPrinter(tablePrinter)
 		tp.SetLastEventErrorThreshold(viper.GetDuration("last-event-error-threshold"))
+		tp.SetColumns(viper.GetStringSlice("columns"), tableColumns)
 
 		printer = tablePrinter
+	case "csv", "tsv":
+		tp := tableprinters.New()
+		// emojis and symbols are meaningless for other tools, the status is printed as textual tokens instead
+		tp.SetPlain(true)
+		tp.SetStatusColumn(viper.GetBool("plain"))
+		tp.SetLastEventErrorThreshold(viper.GetDuration("last-event-error-threshold"))
+		tp.SetColumns(viper.GetStringSlice("columns"), tableColumns)
+
+		delimiter := ','
+		if format == "tsv" {
+			delimiter = '\t'
+		}
+
+		printer = &delimitedPrinter{
+			toHeaderAndRows: tp.ToHeaderAndRows,
+			delimiter:       delimiter,
+			noHeaders:       noHeaders,
+			out:             out,
+		}
 	case "template":
 		printer = printers.NewTemplatePrinter(viper.GetString("template")).WithOut(out)
+	case "jsonpath":
+		printer = &jsonPathPrinter{
+			expression: expression,
+			out:        out,
+		}
+	case "custom-columns", "custom-columns-markdown":
+		columns := &customColumns{spec: expression}
+
+		printer = printers.NewTablePrinter(&printers.TablePrinterConfig{
+			ToHeaderAndRows: columns.ToHeaderAndRows,
+			Markdown:        format == "custom-columns-markdown",
+			NoHeaders:       noHeaders,
+		}).WithOut(out)
 	default:
 		log.Fatalf("unknown output format: %q", format)
 	}
@@ -49,12 +100,206 @@ func newPrinterFromCLI(out io.Writer) printers.Printer {
 		}
 	}
 
+	if viper.GetBool("plain") {
+		color.NoColor = true
+	}
+
 	return printer
 }
 
-func defaultToYAMLPrinter(out io.Writer) printers.Printer {
+func defaultToYAMLPrinter(out io.Writer, tableColumns map[string][]string) printers.Printer {
 	if viper.IsSet("output-format") {
-		return newPrinterFromCLI(out)
+		return newPrinterFromCLI(out, tableColumns)
 	}
 	return printers.NewYAMLPrinter().WithOut(out)
 }
+
+// delimitedPrinter prints the rows of the wide table as csv or tsv, which is meant to be processed by other tools
+type delimitedPrinter struct {
+	toHeaderAndRows func(data any, wide bool) ([]string, [][]string, error)
+	delimiter       rune
+	noHeaders       bool
+	out             io.Writer
+}
+
+func (p *delimitedPrinter) Print(data any) error {
+	if err, ok := data.(error); ok {
+		_, _ = fmt.Fprintf(p.out, "%s\n", err)
+		return nil
+	}
+
+	header, rows, err := p.toHeaderAndRows(data, true)
+	if err != nil {
+		return err
+	}
+
+	w := csv.NewWriter(p.out)
+	w.Comma = p.delimiter
+
+	if !p.noHeaders {
+		err = w.Write(plainCells(header))
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, row := range rows {
+		err = w.Write(plainCells(row))
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+
+	return w.Error()
+}
+
+var ansiEscapeSequence = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// treePrefixRunes build the prefixes of nested rows in tables, e.g. ├─╴
+const treePrefixRunes = "├└─╴ "
+
+// plainCells removes the decorations of table cells, which are colors and spreading values over multiple lines,
+// the tree prefixes of nested rows are removed as well
+func plainCells(cells []string) []string {
+	result := make([]string, 0, len(cells))
+	for _, cell := range cells {
+		cell = ansiEscapeSequence.ReplaceAllString(cell, "")
+		cell = strings.TrimLeft(cell, treePrefixRunes)
+		result = append(result, strings.Join(strings.Fields(cell), " "))
+	}
+	return result
+}
+
+// jsonPathPrinter prints the result of a jsonpath expression like kubectl, lists are wrapped into an object with the elements in items
+type jsonPathPrinter struct {
+	expression string
+	out        io.Writer
+}
+
+func (p *jsonPathPrinter) Print(data any) error {
+	jp, err := parseJSONPath("jsonpath", p.expression)
+	if err != nil {
+		return err
+	}
+
+	obj, err := toJSONObject(data)
+	if err != nil {
+		return err
+	}
+
+	if elems, ok := obj.([]any); ok {
+		obj = map[string]any{"items": elems}
+	}
+
+	err = jp.Execute(p.out, obj)
+	if err != nil {
+		return fmt.Errorf("unable to evaluate jsonpath: %w", err)
+	}
+
+	return nil
+}
+
+// customColumns provides the header and rows for a table with columns defined like NAME:.json.path,OTHER:.path
+type customColumns struct {
+	spec string
+}
+
+func (c *customColumns) ToHeaderAndRows(data any, _ bool) ([]string, [][]string, error) {
+	var (
+		header []string
+		paths  []*jsonpath.JSONPath
+	)
+
+	for column := range strings.SplitSeq(c.spec, ",") {
+		name, expression, ok := strings.Cut(column, ":")
+		if !ok || name == "" || expression == "" {
+			return nil, nil, &usageError{err: fmt.Errorf("invalid custom column %q, must be in the form NAME:.json.path", column)}
+		}
+
+		jp, err := parseJSONPath(name, expression)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		header = append(header, name)
+		paths = append(paths, jp)
+	}
+
+	elems, err := toJSONElements(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows [][]string
+	for _, elem := range elems {
+		var row []string
+		for _, jp := range paths {
+			results, err := jp.FindResults(elem)
+			if err != nil {
+				return nil, nil, fmt.Errorf("unable to evaluate jsonpath: %w", err)
+			}
+
+			var buf bytes.Buffer
+			for _, result := range results {
+				err = jp.PrintResults(&buf, result)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+			row = append(row, buf.String())
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows, nil
+}
+
+// parseJSONPath parses a jsonpath expression, braces can be omitted for a plain path like .allocation.hostname
+func parseJSONPath(name, expression string) (*jsonpath.JSONPath, error) {
+	if expression == "" {
+		return nil, &usageError{err: fmt.Errorf("missing jsonpath expression, e.g. -o jsonpath={.id}")}
+	}
+	if !strings.Contains(expression, "{") {
+		expression = "{." + strings.TrimPrefix(expression, ".") + "}"
+	}
+
+	jp := jsonpath.New(name).AllowMissingKeys(true)
+	err := jp.Parse(expression)
+	if err != nil {
+		return nil, &usageError{err: fmt.Errorf("invalid jsonpath %q: %w", expression, err)}
+	}
+
+	return jp, nil
+}
+
+// toJSONObject converts the data into its json representation, such that the json field names can be used in expressions
+func toJSONObject(data any) (any, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var d any
+	err = json.Unmarshal(raw, &d)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// toJSONElements returns the json representation of every element of a list, or of the single entity
+func toJSONElements(data any) ([]any, error) {
+	d, err := toJSONObject(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if elems, ok := d.([]any); ok {
+		return elems, nil
+	}
+
+	return []any{d}, nil
+}
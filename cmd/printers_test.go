@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-lib/pkg/genericcli/printers"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_delimitedPrinter(t *testing.T) {
+	toHeaderAndRows := func(data any, wide bool) ([]string, [][]string, error) {
+		require.True(t, wide, "delimited output must contain the wide columns")
+		return []string{"ID", "", "Description"}, [][]string{
+			{color.New(color.FgBlue).Sprint("m1"), "DEAD,LOCKED", "with, comma"},
+			{"├─╴m2", "", "with \"quotes\"\nand lines\n\n"},
+		}, nil
+	}
+
+	tests := []struct {
+		name      string
+		delimiter rune
+		noHeaders bool
+		want      string
+	}{
+		{
+			name:      "csv",
+			delimiter: ',',
+			want: `ID,,Description
+m1,"DEAD,LOCKED","with, comma"
+m2,,"with ""quotes"" and lines"
+`,
+		},
+		{
+			name:      "tsv without headers",
+			delimiter: '\t',
+			noHeaders: true,
+			want:      "m1\tDEAD,LOCKED\twith, comma\nm2\t\t\"with \"\"quotes\"\" and lines\"\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := &delimitedPrinter{
+				toHeaderAndRows: toHeaderAndRows,
+				delimiter:       tt.delimiter,
+				noHeaders:       tt.noHeaders,
+				out:             &out,
+			}
+
+			err := p.Print(nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func Test_delimitedPrinter_PlainStatus(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("output-format", "csv")
+
+	var out bytes.Buffer
+	err := newPrinterFromCLI(&out, nil).Print([]*models.V1MachineIPMIResponse{
+		{
+			ID:         new("1"),
+			Liveliness: new("Dead"),
+			State:      &models.V1MachineState{Value: new("LOCKED")},
+			Events:     &models.V1MachineRecentProvisioningEvents{},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, out.String(), "\n1,,\"DEAD,LOCKED\",", "the status must be printed as plain tokens")
+}
+
+func Test_delimitedPrinter_StatusColumn(t *testing.T) {
+	for _, plain := range []bool{false, true} {
+		viper.Reset()
+		viper.Set("output-format", "csv")
+		viper.Set("plain", plain)
+
+		var out bytes.Buffer
+		err := newPrinterFromCLI(&out, nil).Print([]*models.V1MachineResponse{machine1})
+		require.NoError(t, err)
+
+		header, _, _ := strings.Cut(out.String(), "\n")
+		assert.Equal(t, plain, strings.Contains(header, ",Status,"), "the status column must only be printed with --plain")
+	}
+	viper.Reset()
+}
+
+func Test_jsonPathPrinter(t *testing.T) {
+	machines := []*models.V1MachineResponse{
+		{ID: new("1"), Allocation: &models.V1MachineAllocation{Hostname: new("host-1")}, Tags: []string{"a", "b"}},
+		{ID: new("2")},
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		data       any
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:       "single entity",
+			expression: "{.allocation.hostname}",
+			data:       machines[0],
+			want:       "host-1",
+		},
+		{
+			name:       "list without braces",
+			expression: ".items[*].id",
+			data:       machines,
+			want:       "1 2",
+		},
+		{
+			name:       "kubectl style range over items",
+			expression: `{range .items[*]}{.id}{"\t"}{.tags[*]}{"\n"}{end}`,
+			data:       machines,
+			want:       "1\ta b\n2\t\n",
+		},
+		{
+			name:       "missing expression",
+			expression: "",
+			data:       machines,
+			wantErr:    true,
+		},
+		{
+			name:       "invalid expression",
+			expression: "{.id",
+			data:       machines,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := &jsonPathPrinter{expression: tt.expression, out: &out}
+
+			err := p.Print(tt.data)
+			if tt.wantErr {
+				var usageErr *usageError
+				require.ErrorAs(t, err, &usageErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func Test_customColumns(t *testing.T) {
+	machines := []*models.V1MachineResponse{
+		{ID: new("1"), Rackid: "rack-1", Allocation: &models.V1MachineAllocation{Hostname: new("host-1")}},
+		{ID: new("2"), Rackid: "rack-2"},
+	}
+
+	tests := []struct {
+		name      string
+		spec      string
+		markdown  bool
+		noHeaders bool
+		want      string
+		wantErr   bool
+	}{
+		{
+			name: "table",
+			spec: "ID:.id,HOST:.allocation.hostname,RACK:.rackid",
+			want: `ID  HOST    RACK
+1   host-1  rack-1
+2           rack-2
+`,
+		},
+		{
+			name:      "without headers",
+			spec:      "ID:.id,RACK:{.rackid}",
+			noHeaders: true,
+			want: `1  rack-1
+2  rack-2
+`,
+		},
+		{
+			name:     "markdown",
+			spec:     "ID:.id,RACK:.rackid",
+			markdown: true,
+			want: `| ID | RACK   |
+|----|--------|
+| 1  | rack-1 |
+| 2  | rack-2 |
+`,
+		},
+		{
+			name:    "invalid column",
+			spec:    "ID",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := printers.NewTablePrinter(&printers.TablePrinterConfig{
+				ToHeaderAndRows: (&customColumns{spec: tt.spec}).ToHeaderAndRows,
+				Markdown:        tt.markdown,
+				NoHeaders:       tt.noHeaders,
+			}).WithOut(&out)
+
+			err := p.Print(machines)
+			if tt.wantErr {
+				var usageErr *usageError
+				require.ErrorAs(t, err, &usageErr)
+				return
+			}
+			require.NoError(t, err)
+			validateTableRows(t, tt.want, out.String())
+		})
+	}
+}
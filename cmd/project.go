@@ -58,7 +58,7 @@ func newProjectCmd(c *config) *cobra.Command {
 }
 
 func (c *projectCmd) Get(id string) (*models.V1ProjectResponse, error) {
-	resp, err := c.client.Project().FindProject(projectmodel.NewFindProjectParams().WithID(id), nil)
+	resp, err := c.client.Project().FindProject(projectmodel.NewFindProjectParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +71,7 @@ func (c *projectCmd) List() ([]*models.V1ProjectResponse, error) {
 		ID:       viper.GetString("id"),
 		Name:     viper.GetString("name"),
 		TenantID: viper.GetString("tenant"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +80,7 @@ func (c *projectCmd) List() ([]*models.V1ProjectResponse, error) {
 }
 
 func (c *projectCmd) Delete(id string) (*models.V1ProjectResponse, error) {
-	resp, err := c.client.Project().DeleteProject(projectmodel.NewDeleteProjectParams().WithID(id), nil)
+	resp, err := c.client.Project().DeleteProject(projectmodel.NewDeleteProjectParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +89,7 @@ func (c *projectCmd) Delete(id string) (*models.V1ProjectResponse, error) {
 }
 
 func (c *projectCmd) Create(rq *models.V1ProjectCreateRequest) (*models.V1ProjectResponse, error) {
-	resp, err := c.client.Project().CreateProject(projectmodel.NewCreateProjectParams().WithBody(rq), nil)
+	resp, err := c.client.Project().CreateProject(projectmodel.NewCreateProjectParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *projectmodel.CreateProjectConflict
 		if errors.As(err, &r) {
@@ -102,14 +102,14 @@ func (c *projectCmd) Create(rq *models.V1ProjectCreateRequest) (*models.V1Projec
 }
 
 func (c *projectCmd) Update(rq *models.V1ProjectUpdateRequest) (*models.V1ProjectResponse, error) {
-	resp, err := c.client.Project().FindProject(projectmodel.NewFindProjectParams().WithID(rq.Meta.ID), nil)
+	resp, err := c.client.Project().FindProject(projectmodel.NewFindProjectParams().WithID(rq.Meta.ID).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	rq.Meta.Version = resp.Payload.Meta.Version
 
-	updateResp, err := c.client.Project().UpdateProject(projectmodel.NewUpdateProjectParams().WithBody(rq), nil)
+	updateResp, err := c.client.Project().UpdateProject(projectmodel.NewUpdateProjectParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,15 +1,26 @@
 package cmd
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
+	"maps"
+	"net/http"
+	"net/url"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
+	httptransport "github.com/go-openapi/runtime/client"
 	metalgo "github.com/metal-stack/metal-go"
 	"github.com/metal-stack/metal-lib/pkg/genericcli"
 	"github.com/metal-stack/metal-lib/pkg/genericcli/printers"
@@ -19,25 +30,34 @@ import (
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
 type config struct {
+	ctx             context.Context
 	fs              afero.Fs
 	out             io.Writer
 	driverURL       string
 	comp            *completion.Completion
 	client          metalgo.Client
+	token           *bearerToken
+	requestIDs      *requestIDTransport
+	trace           *harRecorder
 	log             *slog.Logger
 	describePrinter printers.Printer
 	listPrinter     printers.Printer
+	tableColumns    map[string][]string
 }
 
 const (
 	binaryName = "metalctl"
+	// unboundFlagsAnnotation marks commands, whose local flags are not bound to viper
+	unboundFlagsAnnotation = "metalctl/unbound-flags"
 )
 
 var (
+	logFormats     = []string{"text", "json"}
 	defaultSSHKeys = [...]string{"id_ed25519", "id_ecdsa", "id_rsa", "id_dsa"}
 	// emptyBody is kind of hack because post with "nil" will result into 406 error from the api
 	emptyBody = []string{}
@@ -52,29 +72,75 @@ func Execute() {
 		comp: &completion.Completion{},
 	}
 
-	err := newRootCmd(c).Execute()
+	// interrupting metalctl cancels the running api calls such that commands can terminate cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := newRootCmd(c).ExecuteContext(ctx)
+	if c.trace != nil {
+		if traceErr := c.trace.write(viper.GetString("trace-file")); traceErr != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "unable to write trace file: %s\n", traceErr)
+		}
+	}
 	if err != nil {
-		if viper.GetBool("debug") {
-			panic(err)
+		err = explainError(err)
+		cliErr := toCLIError(err, c.requestIDs.lastRequestID())
+
+		if viper.GetString("output-format") == "json" {
+			_ = json.NewEncoder(os.Stderr).Encode(cliErr)
+		} else {
+			_, _ = fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		}
-		os.Exit(1)
+
+		stop()
+		os.Exit(cliErr.Code)
+	}
+}
+
+// explainError adds hints to errors, which are otherwise hard to understand
+func explainError(err error) error {
+	var waitErr *waitTimeoutError
+
+	switch {
+	case errors.As(err, &waitErr):
+		return err
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("request timed out after %s, the timeout can be raised with --timeout: %w", viper.GetDuration("timeout"), err)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("interrupted: %w", err)
+	default:
+		return err
 	}
 }
 
 func newRootCmd(c *config) *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:          binaryName,
-		Aliases:      []string{"m"},
-		Short:        "a cli to manage entities in the metal-stack api",
-		SilenceUsage: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		Use:           binaryName,
+		Aliases:       []string{"m"},
+		Short:         "a cli to manage entities in the metal-stack api",
+		Long:          "a cli to manage entities in the metal-stack api\n\n" + exitCodesHelp,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			c.ctx = cmd.Context()
 			viper.SetFs(c.fs)
-			genericcli.Must(viper.BindPFlags(cmd.Flags()))
-			genericcli.Must(viper.BindPFlags(cmd.PersistentFlags()))
+			bindFlags(cmd)
 			// we cannot instantiate the config earlier because
 			// cobra flags do not work so early in the game
-			genericcli.Must(readConfigFile())
-			genericcli.Must(initConfigWithViperCtx(c))
+			err := readConfigFile()
+			if err != nil {
+				return err
+			}
+			err = applyContextDefaults(cmd)
+			if err != nil {
+				return err
+			}
+			// required flags can be given by the context defaults, so they are validated afterwards
+			err = validateFlags(cmd)
+			if err != nil {
+				return err
+			}
+			return initConfigWithViperCtx(c)
 		},
 	}
 
@@ -91,6 +157,9 @@ func newRootCmd(c *config) *cobra.Command {
 	}
 
 	rootCmd.PersistentFlags().StringP("config", "c", "", `alternative config file path, (default is ~/.metalctl/config.yaml).
+Without this flag the config files /etc/metalctl/config.yaml, ~/.metalctl/config.yaml and .metalctl.yaml in the current directory are merged,
+later files take precedence. The .metalctl.yaml may only set the defaults of command flags and the columns of existing contexts.
+Changes are always written to ~/.metalctl/config.yaml.
 Example config.yaml:
 
 ---
@@ -100,9 +169,17 @@ apitoken: "alongtoken"
 `)
 	rootCmd.PersistentFlags().StringP("api-url", "", "", "api server address. Can be specified with METALCTL_API_URL environment variable.")
 	rootCmd.PersistentFlags().String("api-token", "", "api token to authenticate. Can be specified with METALCTL_API_TOKEN environment variable.")
+	rootCmd.PersistentFlags().String("context", "", "the context to use for this command without switching the current context in the config file. Can be specified with METALCTL_CONTEXT environment variable.")
 	rootCmd.PersistentFlags().String("kubeconfig", "", "Path to the kube-config to use for authentication and authorization. Is updated by login. Uses default path if not specified.")
-
-	rootCmd.PersistentFlags().StringP("output-format", "o", "table", "output format (table|wide|markdown|json|yaml|template), wide is a table with more columns.")
+	rootCmd.PersistentFlags().Bool("no-refresh", false, "do not refresh an expired token from the kube-config with the stored refresh token.")
+	rootCmd.PersistentFlags().Duration("timeout", 30*time.Second, "timeout for every request against the metal-api, zero disables the timeout.")
+	rootCmd.PersistentFlags().Duration("token-expiry-warning", 10*time.Minute, "log a warning if the token expires within this duration, zero disables the warning.")
+
+	rootCmd.PersistentFlags().StringP("output-format", "o", "table", `output format (table|wide|markdown|json|yaml|template|csv|tsv|jsonpath=<expression>|custom-columns=<spec>|custom-columns-markdown=<spec>),
+wide is a table with more columns, csv and tsv contain the columns of wide.
+jsonpath is evaluated like in kubectl, lists are available as items, e.g. jsonpath={.items[*].id}
+custom-columns take a comma separated list of columns with a header and a jsonpath, e.g. custom-columns=ID:.id,HOST:.allocation.hostname,
+custom-columns-markdown prints these columns as markdown table.`)
 	rootCmd.PersistentFlags().StringP("template", "", "", `output template for template output-format, go template format.
 For property names inspect the output of -o json or -o yaml for reference.
 Example for machines:
@@ -111,12 +188,29 @@ metalctl machine list -o template --template "{{ .id }}:{{ .size.id  }}"
 
 `)
 	rootCmd.PersistentFlags().Bool("no-headers", false, "do not print headers of table output format (default print headers)")
+	rootCmd.PersistentFlags().StringSlice("columns", nil, `select and order the columns of table, csv and tsv output by name, including the ones only shown in wide mode, e.g. --columns id,hostname,rack.
+Column names are the lowercased headers joined by dashes, an unknown column prints the available ones.
+Only the main table of a command is affected, further tables are printed with their configured columns.
+Columns can also be configured per table in the context, e.g. with: metalctl context set-field <context> columns.machine id,hostname,rack`)
 
 	rootCmd.PersistentFlags().Bool(forceFlag, false, "skips security prompts (which can be dangerous to set blindly because actions can lead to data loss or additional costs)")
 	rootCmd.PersistentFlags().Bool("debug", false, "debug output")
+	rootCmd.PersistentFlags().String("log-format", "text", "format of the log messages, which are written to stderr: "+strings.Join(logFormats, "|"))
+	rootCmd.PersistentFlags().String("log-file", "", "write log messages to the given file instead of stderr.")
+	rootCmd.PersistentFlags().Bool("trace", false, "log every http request against the metal-api with method, url, status, latency and sizes, credentials are redacted.")
+	rootCmd.PersistentFlags().String("trace-file", "", "write all http requests against the metal-api as har archive to the given file, which can be attached to bug reports. Credentials are redacted, bodies are included.")
 	rootCmd.PersistentFlags().Bool("force-color", false, "force colored output even without tty")
+	rootCmd.PersistentFlags().Bool("plain", false, `print textual status tokens like RESERVED, LOCKED, DEAD or LED-ON instead of emojis, symbols and colors in tables.
+Wide machine tables get an additional status column with these tokens.
+Can also be enabled with plain: true in the config file or as context default.`)
+
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
 
 	genericcli.Must(rootCmd.RegisterFlagCompletionFunc("output-format", completion.OutputFormatListCompletion))
+	genericcli.Must(rootCmd.RegisterFlagCompletionFunc("context", c.comp.ContextListCompletion))
+	genericcli.Must(rootCmd.RegisterFlagCompletionFunc("log-format", cobra.FixedCompletions(logFormats, cobra.ShellCompDirectiveNoFileComp)))
 
 	rootCmd.AddCommand(newAuditCmd(c))
 	rootCmd.AddCommand(newFirmwareCmd(c))
@@ -139,10 +233,35 @@ metalctl machine list -o template --template "{{ .id }}:{{ .size.id  }}"
 	rootCmd.AddCommand(newContextCmd(c))
 	rootCmd.AddCommand(newVPNCmd(c))
 	rootCmd.AddCommand(newUpdateCmd(c))
+	rootCmd.AddCommand(newDoctorCmd(c))
+	rootCmd.AddCommand(newCacheCmd(c))
+
+	markUsageErrors(rootCmd)
 
 	return rootCmd
 }
 
+// bindFlags binds the flags of the command to viper, local flags of commands annotated with unboundFlagsAnnotation
+// are left out and have to be read through the command. If such a flag shadows a persistent flag of a parent,
+// the key keeps its value from the config file, the environment or the default of the parent flag.
+func bindFlags(cmd *cobra.Command) {
+	_, unbound := cmd.Annotations[unboundFlagsAnnotation]
+	local := cmd.LocalNonPersistentFlags()
+
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if unbound && local.Lookup(f.Name) != nil {
+			cmd.VisitParents(func(parent *cobra.Command) {
+				if pf := parent.PersistentFlags().Lookup(f.Name); pf != nil {
+					genericcli.Must(viper.BindPFlag(f.Name, pf))
+				}
+			})
+			return
+		}
+		genericcli.Must(viper.BindPFlag(f.Name, f))
+	})
+	genericcli.Must(viper.BindPFlags(cmd.PersistentFlags()))
+}
+
 func readConfigFile() error {
 	viper.SetEnvPrefix(strings.ToUpper(binaryName))
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -156,23 +275,60 @@ func readConfigFile() error {
 		if err := viper.ReadInConfig(); err != nil {
 			return fmt.Errorf("config file path set explicitly, but unreadable: %w", err)
 		}
-	} else {
-		viper.SetConfigName("config")
-		viper.AddConfigPath(fmt.Sprintf("/etc/%s", binaryName))
+		return nil
+	}
+
+	// the config layers are merged, later layers take precedence. The project config only provides context defaults
+	// and columns, which are read with the contexts.
+	for _, layer := range api.TrustedConfigLayers() {
+		viper.SetConfigFile(layer)
+		if err := viper.MergeInConfig(); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("config %s file unreadable: %w", layer, err)
+		}
+	}
+
+	return nil
+}
+
+// applyContextDefaults sets the flag defaults of the current context for all flags of the given command,
+// which were neither given explicitly nor through the environment
+func applyContextDefaults(cmd *cobra.Command) error {
+	ctx := api.MustDefaultContext()
+
+	defaults := maps.Clone(ctx.Defaults)
+	if defaults == nil {
+		defaults = map[string]string{}
+	}
+	for flag, value := range ctx.ProjectDefaults {
+		// the project config must not change global flags like the api url or the credentials
+		if cmd.LocalNonPersistentFlags().Lookup(flag) == nil {
+			continue
+		}
+		defaults[flag] = value
+	}
+
+	for flag, value := range defaults {
+		f := cmd.Flags().Lookup(flag)
+		if f == nil || f.Changed {
+			continue
+		}
+
+		envName := strings.ToUpper(binaryName + "_" + strings.ReplaceAll(flag, "-", "_"))
+		if _, ok := os.LookupEnv(envName); ok {
+			continue
+		}
 
-		h, err := os.UserHomeDir()
+		err := f.Value.Set(value)
 		if err != nil {
-			return fmt.Errorf("unable to figure out user home directory, skipping config lookup path: %w", err)
-		} else {
-			viper.AddConfigPath(fmt.Sprintf(h+"/.%s", binaryName))
+			return fmt.Errorf("invalid default for flag %q in context: %w", flag, err)
 		}
 
-		viper.AddConfigPath(".")
-		if err := viper.ReadInConfig(); err != nil {
-			usedCfg := viper.ConfigFileUsed()
-			if usedCfg != "" {
-				return fmt.Errorf("config %s file unreadable: %w", usedCfg, err)
-			}
+		// a context default satisfies required flags
+		if _, ok := f.Annotations[cobra.BashCompOneRequiredFlag]; ok {
+			f.Changed = true
 		}
 	}
 
@@ -180,96 +336,309 @@ func readConfigFile() error {
 }
 
 func initConfigWithViperCtx(c *config) error {
+	if name := viper.GetString("context"); name != "" {
+		ctxs, err := api.GetContexts()
+		if err != nil {
+			return err
+		}
+		if _, ok := ctxs.Contexts[name]; !ok {
+			return fmt.Errorf("context %s not found", name)
+		}
+	}
+
 	ctx := api.MustDefaultContext()
 
-	c.listPrinter = newPrinterFromCLI(c.out)
-	c.describePrinter = defaultToYAMLPrinter(c.out)
+	// the generated request parameters of metal-go are initialized with this timeout
+	httptransport.DefaultTimeout = viper.GetDuration("timeout")
+
+	c.listPrinter = newPrinterFromCLI(c.out, ctx.Columns)
+	c.describePrinter = defaultToYAMLPrinter(c.out, ctx.Columns)
+	c.tableColumns = ctx.Columns
 
 	if c.log == nil {
-		opts := &slog.HandlerOptions{}
-		if viper.GetBool("debug") {
-			opts.Level = slog.LevelDebug
+		log, err := newLogger()
+		if err != nil {
+			return err
 		}
-		jsonHandler := slog.NewJSONHandler(os.Stdout, opts)
-		c.log = slog.New(jsonHandler)
+		c.log = log
 	}
 
 	if c.client != nil {
 		return nil
 	}
 
-	driverURL := viper.GetString("api-url")
-	if driverURL == "" && ctx.ApiURL != "" {
-		driverURL = ctx.ApiURL
-	}
-
-	var (
-		clientOptions []metalgo.ClientOption
-		err           error
-	)
+	driverURL := contextAPIURL(ctx)
 
 	hmacKey := viper.GetString("hmac")
 	if hmacKey == "" && ctx.HMAC != nil {
 		hmacKey = *ctx.HMAC
 	}
+	apiToken := viper.GetString("api-token")
 	hmacAuthType := viper.GetString("hmac-auth-type")
 	if hmacAuthType == "" && ctx.HMACAuthType != "" {
 		hmacAuthType = ctx.HMACAuthType
 	}
-	clientOptions = append(clientOptions, metalgo.HMACAuth(hmacKey, hmacAuthType))
 
-	apiToken := viper.GetString("api-token")
+	token := &bearerToken{token: apiToken}
+
+	contextName := ""
+	if ctxs, err := api.GetContexts(); err == nil {
+		contextName = ctxs.ActiveContextName()
+	}
+
+	// a context logged in with client credentials uses the token from the token cache,
+	// which is re-acquired when it expires
+	if hmacKey == "" && apiToken == "" {
+		ccConfig := api.ClientCredentialsConfig{
+			IssuerURL: ctx.IssuerURL,
+			ClientID:  ctx.ClientID,
+			Scopes:    splitScopes(ctx.CustomScopes),
+			ApiURL:    driverURL,
+		}
+
+		cached, err := api.CachedClientCredentialsToken(contextName, ccConfig)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			c.log.Debug("not using cached client credentials token", "error", err)
+		}
+		if err == nil {
+			token.token = cached.Token
+			token.refresh = func(reqCtx context.Context) (string, error) {
+				clientSecret, err := contextClientSecret(ctx)
+				if err != nil {
+					return "", err
+				}
+				config := ccConfig
+				config.ClientSecret = clientSecret
+				t, err := api.AcquireClientCredentialsToken(reqCtx, contextName, config)
+				if err != nil {
+					return "", err
+				}
+				return t.Token, nil
+			}
+
+			if cached.Expired() {
+				_, err := token.renew(c.ctx, token.get())
+				if err != nil {
+					c.log.Debug("unable to re-acquire expired client credentials token", "error", err)
+				}
+			}
+		}
+	}
+
 	// if there is no api token explicitly specified we try to pull it out of the kubeconfig context
-	if apiToken == "" {
-		authContext, err := getAuthContext(viper.GetString("kubeconfig"))
+	if hmacKey == "" && token.get() == "" {
+		kubeconfig := viper.GetString("kubeconfig")
+		authContext, err := getAuthContext(kubeconfig)
 		// if there is an error, no kubeconfig exists for us ... this is not really an error
 		// since metalctl can be used in scripting with an hmac-key
 		if err == nil {
-			apiToken = authContext.IDToken
+			token.token = authContext.IDToken
+
+			if !viper.GetBool("no-refresh") {
+				token.refresh = func(ctx context.Context) (string, error) {
+					return api.RefreshToken(ctx, kubeconfig, authContext, nil)
+				}
+
+				if api.TokenExpired(token.token) {
+					_, err := token.renew(c.ctx, token.get())
+					if err != nil {
+						c.log.Debug("unable to refresh expired token", "error", err)
+					}
+				}
+			}
 		}
 	}
-	clientOptions = append(clientOptions, metalgo.BearerToken(apiToken))
 
-	certificateAuthorityData := viper.GetString("certificate-authority-data")
-	if certificateAuthorityData == "" && ctx.CertificateAuthorityData != "" {
-		certificateAuthorityData = ctx.CertificateAuthorityData
+	if certificateAuthorityData := viper.GetString("certificate-authority-data"); certificateAuthorityData != "" {
+		ctx.CertificateAuthorityData = certificateAuthorityData
+		ctx.CertificateAuthorityFile = ""
 	}
-	if certificateAuthorityData != "" {
-		tlsClientConfig, err := createTLSClientConfig([]byte(certificateAuthorityData))
-		if err != nil {
-			return err
+	if ctx.InsecureSkipVerify {
+		c.log.Warn("verification of the metal-api server certificate is disabled by insecure_skip_verify in the context, the connection is not secure")
+	}
+
+	transport, err := createTransport(ctx)
+	if err != nil {
+		return err
+	}
+
+	var auth authenticator
+	switch {
+	case hmacKey != "":
+		auth = hmacAuthenticator(hmacAuthType, hmacKey)
+	case token.get() != "":
+		auth = token
+	}
+
+	// the credential helper is not run before the first request, such that commands without api calls
+	// like completion or context do not depend on it
+	if hmacKey == "" && apiToken == "" && ctx.CredentialHelper != nil {
+		auth = &credentialHelperAuth{
+			helper:       ctx.CredentialHelper,
+			hmacAuthType: hmacAuthType,
+			token:        token,
+			fallback:     auth,
+		}
+	}
+
+	warnTokenExpiry(c.log, token.get(), viper.GetDuration("token-expiry-warning"))
+
+	var next http.RoundTripper = transport
+	if viper.GetBool("trace") || viper.GetString("trace-file") != "" {
+		traceLog := slog.New(slog.DiscardHandler)
+		if viper.GetBool("trace") {
+			traceLog = c.log
+		}
+		if viper.GetString("trace-file") != "" {
+			c.trace = &harRecorder{}
 		}
+		next = &traceTransport{
+			next:     transport,
+			log:      traceLog,
+			recorder: c.trace,
+		}
+	}
 
-		clientOptions = append(clientOptions, metalgo.TLSClientConfig(tlsClientConfig))
+	requestIDs := &requestIDTransport{
+		next: &refreshTransport{
+			next: &retryTransport{
+				next:   next,
+				policy: ctx.RetryPolicy(),
+				log:    c.log,
+				auth:   auth,
+			},
+			token: token,
+			log:   c.log,
+		},
+	}
+
+	cache, err := completion.NewCache(contextName, driverURL, credentialIdentity(auth, hmacAuthType), ctx.CompletionCacheExpiry())
+	if err != nil {
+		c.log.Debug("completion cache is disabled", "error", err)
 	}
 
-	client, err := metalgo.NewClient(driverURL, clientOptions...)
+	client, err := newMetalClient(driverURL, &cacheInvalidationTransport{
+		next:  requestIDs,
+		cache: cache,
+		log:   c.log,
+	}, auth)
 	if err != nil {
 		return err
 	}
 
 	c.comp.SetClient(client)
+	c.comp.SetCache(cache)
 	c.driverURL = driverURL
 	c.client = client
+	c.token = token
+	c.requestIDs = requestIDs
 
 	return nil
 }
 
-func createTLSClientConfig(caData []byte) (*tls.Config, error) {
-	caCert := make([]byte, base64.StdEncoding.DecodedLen(len(caData)))
-	_, err := base64.StdEncoding.Decode(caCert, caData)
+// newLogger creates the logger for operational messages, which are never written to stdout
+// such that the command output stays machine-readable
+func newLogger() (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{}
+	if viper.GetBool("debug") {
+		opts.Level = slog.LevelDebug
+	}
+
+	var w io.Writer = os.Stderr
+	if path := viper.GetString("log-file"); path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("unable to open log file: %w", err)
+		}
+		w = f
+	}
+
+	switch format := viper.GetString("log-format"); format {
+	case "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	default:
+		return nil, fmt.Errorf("unsupported log format %q, must be one of: %s", format, strings.Join(logFormats, "|"))
+	}
+}
+
+// createTransport creates the http transport for the metal-api from the tls and proxy settings of the context
+func createTransport(ctx api.Context) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	tlsClientConfig, err := createTLSClientConfig(ctx)
 	if err != nil {
 		return nil, err
 	}
+	transport.TLSClientConfig = tlsClientConfig
+
+	if ctx.ProxyURL != "" {
+		proxyURL, err := url.Parse(ctx.ProxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("proxy url is invalid: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	return transport, nil
+}
 
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
-	tlsClientConfig := tls.Config{
-		RootCAs:    caCertPool,
-		MinVersion: tls.VersionTLS12,
+func createTLSClientConfig(ctx api.Context) (*tls.Config, error) {
+	tlsClientConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         ctx.TLSServerName,
+		InsecureSkipVerify: ctx.InsecureSkipVerify, //nolint:gosec
 	}
 
-	return &tlsClientConfig, nil
+	caCert, err := readPEM(ctx.CertificateAuthorityData, ctx.CertificateAuthorityFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read certificate authority: %w", err)
+	}
+	if caCert != nil {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("certificate authority does not contain any valid pem encoded certificate")
+		}
+		tlsClientConfig.RootCAs = caCertPool
+	}
+
+	clientCert, err := readPEM(ctx.ClientCertificateData, ctx.ClientCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client certificate: %w", err)
+	}
+	clientKey, err := readPEM(ctx.ClientKeyData, ctx.ClientKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read client key: %w", err)
+	}
+	if clientCert != nil || clientKey != nil {
+		if clientCert == nil || clientKey == nil {
+			return nil, fmt.Errorf("client certificate and client key must be set together")
+		}
+		keyPair, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate is invalid: %w", err)
+		}
+		tlsClientConfig.Certificates = []tls.Certificate{keyPair}
+	}
+
+	return tlsClientConfig, nil
+}
+
+// readPEM returns the pem from the base64 encoded data or from the file at the given path, nil if none is given
+func readPEM(data, path string) ([]byte, error) {
+	switch {
+	case data != "":
+		return base64.StdEncoding.DecodeString(data)
+	case path != "":
+		path, err := expandFilepath(path)
+		if err != nil {
+			return nil, err
+		}
+		return os.ReadFile(path)
+	default:
+		return nil, nil
+	}
 }
 
 func recursiveAutoGenDisable(cmd *cobra.Command) {
@@ -1,17 +1,35 @@
 package cmd
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
 	"fmt"
+	"io"
+	"log"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
+	httptransport "github.com/go-openapi/runtime/client"
 	"github.com/metal-stack/metal-go/api/models"
 	"github.com/metal-stack/metal-lib/pkg/healthstatus"
 	"github.com/metal-stack/metal-lib/rest"
+	"github.com/metal-stack/metalctl/pkg/api"
 	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -117,3 +135,323 @@ api-token: "i-am-token"
 		tt.testCmd(t)
 	}
 }
+
+func Test_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer ts.Close()
+
+	t.Cleanup(func() {
+		httptransport.DefaultTimeout = 30 * time.Second
+	})
+
+	tt := &test[*rest.HealthResponse]{
+		name: "timeout",
+		cmd: func(want *rest.HealthResponse) []string {
+			return []string{"health", "--api-url", ts.URL, "--api-token", "i-am-token", "--timeout", "100ms"}
+		},
+		disableMockClient: true,
+	}
+
+	_, _, config := tt.newMockConfig(t)
+	viper.Reset()
+
+	cmd := newRootCmd(config)
+	os.Args = append([]string{binaryName}, tt.cmd(nil)...)
+
+	err := cmd.Execute()
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, explainError(err), "request timed out after 100ms, the timeout can be raised with --timeout")
+}
+
+func Test_applyContextDefaults(t *testing.T) {
+	cfgFile := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(cfgFile, []byte(`---
+current: prod
+contexts:
+  prod:
+    url: https://api.metal-stack.io/metal
+    defaults:
+      project: default-project
+      partition: default-partition
+      size: default-size
+      tags: a,b
+      unknown-flag: foo
+`), 0600))
+
+	t.Setenv("METALCTL_SIZE", "env-size")
+
+	viper.Reset()
+	defer viper.Reset()
+	viper.Set("config", cfgFile)
+	viper.SetEnvPrefix(strings.ToUpper(binaryName))
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	viper.AutomaticEnv()
+
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("project", "", "")
+	cmd.Flags().String("partition", "", "")
+	cmd.Flags().String("size", "", "")
+	cmd.Flags().StringSlice("tags", nil, "")
+	require.NoError(t, cmd.MarkFlagRequired("partition"))
+	require.NoError(t, cmd.ParseFlags([]string{"--project", "flag-project"}))
+	require.NoError(t, viper.BindPFlags(cmd.Flags()))
+
+	require.NoError(t, applyContextDefaults(cmd))
+
+	assert.Equal(t, "flag-project", viper.GetString("project"))
+	assert.Equal(t, "default-partition", viper.GetString("partition"))
+	assert.Equal(t, "env-size", viper.GetString("size"))
+	assert.Equal(t, []string{"a", "b"}, viper.GetStringSlice("tags"))
+	require.NoError(t, cmd.ValidateRequiredFlags())
+}
+
+func Test_bindFlags(t *testing.T) {
+	viper.Reset()
+	defer viper.Reset()
+
+	root := &cobra.Command{Use: "root"}
+	root.PersistentFlags().Bool("debug", false, "")
+	root.PersistentFlags().Duration("timeout", 30*time.Second, "")
+
+	add := &cobra.Command{
+		Use:         "add",
+		Annotations: map[string]string{unboundFlagsAnnotation: "true"},
+		Run:         func(cmd *cobra.Command, args []string) {},
+	}
+	add.Flags().String("hmac", "", "")
+	add.Flags().Duration("timeout", 30*time.Minute, "")
+	root.AddCommand(add)
+
+	root.SetArgs([]string{"add", "--hmac", "context-hmac", "--debug", "--timeout", "1m"})
+	cmd, err := root.ExecuteC()
+	require.NoError(t, err)
+
+	bindFlags(cmd)
+
+	assert.Empty(t, viper.GetString("hmac"))
+	assert.True(t, viper.GetBool("debug"))
+	// the shadowed persistent flag of the parent stays bound
+	assert.Equal(t, 30*time.Second, viper.GetDuration("timeout"))
+}
+
+func Test_createTransport(t *testing.T) {
+	ca := newTestCertificate(t, nil, "metal-stack-ca")
+	serverCert := newTestCertificate(t, ca, "metal-api.test")
+	clientCert := newTestCertificate(t, ca, "metalctl")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverCert.keyPair(t)},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	require.NoError(t, os.WriteFile(caFile, ca.certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, clientCert.keyPEM, 0600))
+
+	validCtx := api.Context{
+		CertificateAuthorityFile: caFile,
+		ClientCertificateData:    base64.StdEncoding.EncodeToString(clientCert.certPEM),
+		ClientKey:                keyFile,
+		TLSServerName:            "metal-api.test",
+	}
+
+	tests := []struct {
+		name       string
+		ctx        func(ctx api.Context) api.Context
+		want       string
+		wantErr    string
+		wantReqErr string
+	}{
+		{
+			name: "mutual tls",
+			ctx:  func(ctx api.Context) api.Context { return ctx },
+			want: "metalctl",
+		},
+		{
+			name: "ca data",
+			ctx: func(ctx api.Context) api.Context {
+				ctx.CertificateAuthorityFile = ""
+				ctx.CertificateAuthorityData = base64.StdEncoding.EncodeToString(ca.certPEM)
+				return ctx
+			},
+			want: "metalctl",
+		},
+		{
+			name: "server name mismatch",
+			ctx: func(ctx api.Context) api.Context {
+				ctx.TLSServerName = ""
+				return ctx
+			},
+			wantReqErr: "cannot validate certificate for 127.0.0.1",
+		},
+		{
+			name: "insecure skip verify",
+			ctx: func(ctx api.Context) api.Context {
+				ctx.TLSServerName = ""
+				ctx.CertificateAuthorityFile = ""
+				ctx.InsecureSkipVerify = true
+				return ctx
+			},
+			want: "metalctl",
+		},
+		{
+			name: "no client certificate",
+			ctx: func(ctx api.Context) api.Context {
+				ctx.ClientCertificateData = ""
+				ctx.ClientKey = ""
+				return ctx
+			},
+			wantReqErr: "certificate required",
+		},
+		{
+			name: "client key missing",
+			ctx: func(ctx api.Context) api.Context {
+				ctx.ClientKey = ""
+				return ctx
+			},
+			wantErr: "client certificate and client key must be set together",
+		},
+		{
+			name: "invalid ca file",
+			ctx: func(ctx api.Context) api.Context {
+				ctx.CertificateAuthorityFile = keyFile
+				return ctx
+			},
+			wantErr: "certificate authority does not contain any valid pem encoded certificate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, err := createTransport(tt.ctx(validCtx))
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if tt.wantReqErr != "" {
+				require.ErrorContains(t, err, tt.wantReqErr)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(body))
+		})
+	}
+}
+
+func Test_createTransportProxy(t *testing.T) {
+	transport, err := createTransport(api.Context{ProxyURL: "http://proxy.example:3128"})
+	require.NoError(t, err)
+
+	proxyURL, err := transport.Proxy(httptest.NewRequest(http.MethodGet, "https://api.metal-stack.io/metal", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.example:3128", proxyURL.String())
+}
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCertificate creates a certificate signed by the given parent, a self-signed ca if parent is nil
+func newTestCertificate(t *testing.T, parent *testCertificate, commonName string) *testCertificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     []string{commonName},
+	}
+
+	signerCert, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signerCert, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func (c *testCertificate) keyPair(t *testing.T) tls.Certificate {
+	keyPair, err := tls.X509KeyPair(c.certPEM, c.keyPEM)
+	require.NoError(t, err)
+	return keyPair
+}
+
+func Test_newLogger(t *testing.T) {
+	t.Cleanup(viper.Reset)
+
+	path := filepath.Join(t.TempDir(), "metalctl.log")
+
+	viper.Set("log-file", path)
+	viper.Set("log-format", "json")
+	viper.Set("debug", true)
+
+	log, err := newLogger()
+	require.NoError(t, err)
+	log.Debug("switched context", "context", "prod")
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"level":"DEBUG","msg":"switched context","context":"prod"`)
+
+	viper.Set("log-format", "text")
+	viper.Set("debug", false)
+
+	log, err = newLogger()
+	require.NoError(t, err)
+	log.Debug("hidden")
+	log.Info("using ssh identity", "identity", "id_ed25519")
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "hidden")
+	assert.Contains(t, string(content), `level=INFO msg="using ssh identity" identity=id_ed25519`)
+
+	viper.Set("log-format", "logfmt")
+	_, err = newLogger()
+	require.EqualError(t, err, `unsupported log format "logfmt", must be one of: text|json`)
+}
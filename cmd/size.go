@@ -82,7 +82,7 @@ func newSizeCmd(c *config) *cobra.Command {
 }
 
 func (c *sizeCmd) Get(id string) (*models.V1SizeResponse, error) {
-	resp, err := c.client.Size().FindSize(size.NewFindSizeParams().WithID(id), nil)
+	resp, err := c.client.Size().FindSize(size.NewFindSizeParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +91,7 @@ func (c *sizeCmd) Get(id string) (*models.V1SizeResponse, error) {
 }
 
 func (c *sizeCmd) List() ([]*models.V1SizeResponse, error) {
-	resp, err := c.client.Size().ListSizes(size.NewListSizesParams(), nil)
+	resp, err := c.client.Size().ListSizes(size.NewListSizesParams().WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +100,7 @@ func (c *sizeCmd) List() ([]*models.V1SizeResponse, error) {
 }
 
 func (c *sizeCmd) Delete(id string) (*models.V1SizeResponse, error) {
-	resp, err := c.client.Size().DeleteSize(size.NewDeleteSizeParams().WithID(id), nil)
+	resp, err := c.client.Size().DeleteSize(size.NewDeleteSizeParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -109,7 +109,7 @@ func (c *sizeCmd) Delete(id string) (*models.V1SizeResponse, error) {
 }
 
 func (c *sizeCmd) Create(rq *models.V1SizeCreateRequest) (*models.V1SizeResponse, error) {
-	resp, err := c.client.Size().CreateSize(size.NewCreateSizeParams().WithBody(rq), nil)
+	resp, err := c.client.Size().CreateSize(size.NewCreateSizeParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *size.CreateSizeConflict
 		if errors.As(err, &r) {
@@ -122,7 +122,7 @@ func (c *sizeCmd) Create(rq *models.V1SizeCreateRequest) (*models.V1SizeResponse
 }
 
 func (c *sizeCmd) Update(rq *models.V1SizeUpdateRequest) (*models.V1SizeResponse, error) {
-	resp, err := c.client.Size().UpdateSize(size.NewUpdateSizeParams().WithBody(rq), nil)
+	resp, err := c.client.Size().UpdateSize(size.NewUpdateSizeParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +197,7 @@ func (c *sizeCmd) suggest(args []string) error {
 
 	resp, err := c.client.Size().Suggest(size.NewSuggestParams().WithBody(&models.V1SizeSuggestRequest{
 		MachineID: &machineid,
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -111,6 +111,8 @@ func newSizeReservationsCmd(c *config) *cobra.Command {
 		},
 	}
 
+	c.addWatchFlags(usageCmd)
+
 	usageCmd.Flags().String("size-id", "", "the size-id to filter")
 	usageCmd.Flags().String("project", "", "the project to filter")
 	usageCmd.Flags().String("partition", "", "the partition to filter")
@@ -125,7 +127,7 @@ func newSizeReservationsCmd(c *config) *cobra.Command {
 }
 
 func (c *sizeReservationsCmd) Get(id string) (*models.V1SizeReservationResponse, error) {
-	resp, err := c.client.Size().GetSizeReservation(sizemodel.NewGetSizeReservationParams().WithID(id), nil)
+	resp, err := c.client.Size().GetSizeReservation(sizemodel.NewGetSizeReservationParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -139,7 +141,7 @@ func (c *sizeReservationsCmd) List() ([]*models.V1SizeReservationResponse, error
 		Partitionid: viper.GetString("partition"),
 		Projectid:   viper.GetString("project"),
 		Sizeid:      viper.GetString("size"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -148,7 +150,7 @@ func (c *sizeReservationsCmd) List() ([]*models.V1SizeReservationResponse, error
 }
 
 func (c *sizeReservationsCmd) Delete(id string) (*models.V1SizeReservationResponse, error) {
-	resp, err := c.client.Size().DeleteSizeReservation(sizemodel.NewDeleteSizeReservationParams().WithID(id), nil)
+	resp, err := c.client.Size().DeleteSizeReservation(sizemodel.NewDeleteSizeReservationParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -157,7 +159,7 @@ func (c *sizeReservationsCmd) Delete(id string) (*models.V1SizeReservationRespon
 }
 
 func (c *sizeReservationsCmd) Create(rq *models.V1SizeReservationCreateRequest) (*models.V1SizeReservationResponse, error) {
-	resp, err := c.client.Size().CreateSizeReservation(sizemodel.NewCreateSizeReservationParams().WithBody(rq), nil)
+	resp, err := c.client.Size().CreateSizeReservation(sizemodel.NewCreateSizeReservationParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *sizemodel.CreateSizeReservationConflict
 		if errors.As(err, &r) {
@@ -170,7 +172,7 @@ func (c *sizeReservationsCmd) Create(rq *models.V1SizeReservationCreateRequest)
 }
 
 func (c *sizeReservationsCmd) Update(rq *models.V1SizeReservationUpdateRequest) (*models.V1SizeReservationResponse, error) {
-	resp, err := c.client.Size().UpdateSizeReservation(sizemodel.NewUpdateSizeReservationParams().WithBody(rq), nil)
+	resp, err := c.client.Size().UpdateSizeReservation(sizemodel.NewUpdateSizeReservationParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -221,7 +223,7 @@ func (c *sizeReservationsCmd) usage() error {
 		Partitionid: viper.GetString("partition"),
 		Projectid:   viper.GetString("project"),
 		Sizeid:      viper.GetString("size"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
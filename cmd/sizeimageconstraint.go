@@ -52,7 +52,7 @@ func newSizeImageConstraintCmd(c *config) *cobra.Command {
 }
 
 func (c *sizeImageConstraintCmd) Get(id string) (*models.V1SizeImageConstraintResponse, error) {
-	resp, err := c.client.Sizeimageconstraint().FindSizeImageConstraint(sizemodel.NewFindSizeImageConstraintParams().WithID(id), nil)
+	resp, err := c.client.Sizeimageconstraint().FindSizeImageConstraint(sizemodel.NewFindSizeImageConstraintParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -61,7 +61,7 @@ func (c *sizeImageConstraintCmd) Get(id string) (*models.V1SizeImageConstraintRe
 }
 
 func (c *sizeImageConstraintCmd) List() ([]*models.V1SizeImageConstraintResponse, error) {
-	resp, err := c.client.Sizeimageconstraint().ListSizeImageConstraints(sizemodel.NewListSizeImageConstraintsParams(), nil)
+	resp, err := c.client.Sizeimageconstraint().ListSizeImageConstraints(sizemodel.NewListSizeImageConstraintsParams().WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -70,7 +70,7 @@ func (c *sizeImageConstraintCmd) List() ([]*models.V1SizeImageConstraintResponse
 }
 
 func (c *sizeImageConstraintCmd) Delete(id string) (*models.V1SizeImageConstraintResponse, error) {
-	resp, err := c.client.Sizeimageconstraint().DeleteSizeImageConstraint(sizemodel.NewDeleteSizeImageConstraintParams().WithID(id), nil)
+	resp, err := c.client.Sizeimageconstraint().DeleteSizeImageConstraint(sizemodel.NewDeleteSizeImageConstraintParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +79,7 @@ func (c *sizeImageConstraintCmd) Delete(id string) (*models.V1SizeImageConstrain
 }
 
 func (c *sizeImageConstraintCmd) Create(rq *models.V1SizeImageConstraintCreateRequest) (*models.V1SizeImageConstraintResponse, error) {
-	resp, err := c.client.Sizeimageconstraint().CreateSizeImageConstraint(sizemodel.NewCreateSizeImageConstraintParams().WithBody(rq), nil)
+	resp, err := c.client.Sizeimageconstraint().CreateSizeImageConstraint(sizemodel.NewCreateSizeImageConstraintParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *sizemodel.CreateSizeImageConstraintConflict
 		if errors.As(err, &r) {
@@ -92,7 +92,7 @@ func (c *sizeImageConstraintCmd) Create(rq *models.V1SizeImageConstraintCreateRe
 }
 
 func (c *sizeImageConstraintCmd) Update(rq *models.V1SizeImageConstraintUpdateRequest) (*models.V1SizeImageConstraintResponse, error) {
-	resp, err := c.client.Sizeimageconstraint().UpdateSizeImageConstraint(sizemodel.NewUpdateSizeImageConstraintParams().WithBody(rq), nil)
+	resp, err := c.client.Sizeimageconstraint().UpdateSizeImageConstraint(sizemodel.NewUpdateSizeImageConstraintParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,7 +131,7 @@ func (c *sizeImageConstraintCmd) try() error {
 	_, err := c.client.Sizeimageconstraint().TrySizeImageConstraint(sizemodel.NewTrySizeImageConstraintParams().WithBody(&models.V1SizeImageConstraintTryRequest{
 		Size:  new(viper.GetString("size")),
 		Image: new(viper.GetString("image")),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -23,12 +22,11 @@ func (c *firewallCmd) firewallSSHViaVPN(firewall *models.V1FirewallResponse) (er
 	authKeyResp, err := c.client.VPN().GetVPNAuthKey(vpn.NewGetVPNAuthKeyParams().WithBody(&models.V1VPNRequest{
 		Pid:       projectID,
 		Ephemeral: new(true),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return fmt.Errorf("failed to get VPN auth key: %w", err)
 	}
-	ctx := context.Background()
-	v, err := metalvpn.Connect(ctx, *firewall.ID, *authKeyResp.Payload.Address, *authKeyResp.Payload.AuthKey)
+	v, err := metalvpn.Connect(c.ctx, *firewall.ID, *authKeyResp.Payload.Address, *authKeyResp.Payload.AuthKey)
 	if err != nil {
 		return err
 	}
@@ -57,7 +55,7 @@ func (c *firewallCmd) firewallSSHViaVPN(firewall *models.V1FirewallResponse) (er
 }
 
 // sshClient opens an interactive ssh session to the host on port with user, authenticated by the key.
-func sshClient(user, keyfile, host string, port int, idToken *string, passwordAuth bool) error {
+func (c *config) sshClient(user, keyfile, host string, port int, idToken *string, passwordAuth bool) error {
 
 	var opts []metalssh.ConnectOpt
 	if passwordAuth {
@@ -65,7 +63,7 @@ func sshClient(user, keyfile, host string, port int, idToken *string, passwordAu
 	} else {
 		if keyfile == "" {
 			var err error
-			keyfile, err = searchSSHKey()
+			keyfile, err = c.searchSSHKey()
 			if err != nil {
 				return err
 			}
@@ -45,6 +45,7 @@ func newSwitchCmd(c *config) *cobra.Command {
 		DescribePrinter: func() printers.Printer { return c.describePrinter },
 		ListPrinter:     func() printers.Printer { return c.listPrinter },
 		ListCmdMutateFn: func(cmd *cobra.Command) {
+			c.addWatchFlags(cmd)
 			cmd.Flags().String("id", "", "ID of the switch.")
 			cmd.Flags().String("name", "", "Name of the switch.")
 			cmd.Flags().String("os-vendor", "", "OS vendor of this switch.")
@@ -93,7 +94,11 @@ func newSwitchCmd(c *config) *cobra.Command {
 		},
 		Example: `The command will show the machines connected to the switch ports.
 
-Can also be used with -o template in order to generate CSV-style output:
+Can also be used with -o csv or -o tsv in order to process the output with other tools:
+
+$ metalctl switch connected-machines -o csv
+
+For custom CSV-style output -o template can be used:
 
 $ metalctl switch connected-machines -o template --template '{{ $machines := .machines }}{{ range .switches }}{{ $switch := . }}{{ range .connections }}{{ $switch.id }},{{ $switch.rack_id }},{{ .nic.name }},{{ .machine_id }},{{ (index $machines .machine_id).ipmi.fru.product_serial }}{{ printf "\n" }}{{ end }}{{ end }}'
 r01leaf01,swp1,f78cc340-e5e8-48ed-8fe7-2336c1e2ded2,<a-serial>
@@ -208,7 +213,7 @@ Operational steps to replace a switch:
 }
 
 func (c *switchCmd) Get(id string) (*models.V1SwitchResponse, error) {
-	resp, err := c.client.SwitchOperations().FindSwitch(switch_operations.NewFindSwitchParams().WithID(id), nil)
+	resp, err := c.client.SwitchOperations().FindSwitch(switch_operations.NewFindSwitchParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -224,7 +229,7 @@ func (c *switchCmd) List() ([]*models.V1SwitchResponse, error) {
 		Osversion:   viper.GetString("os-version"),
 		Partitionid: viper.GetString("partition"),
 		Rackid:      viper.GetString("rack"),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -233,7 +238,7 @@ func (c *switchCmd) List() ([]*models.V1SwitchResponse, error) {
 }
 
 func (c *switchCmd) Delete(id string) (*models.V1SwitchResponse, error) {
-	resp, err := c.client.SwitchOperations().DeleteSwitch(switch_operations.NewDeleteSwitchParams().WithID(id).WithForce(new(viper.GetBool("force"))), nil)
+	resp, err := c.client.SwitchOperations().DeleteSwitch(switch_operations.NewDeleteSwitchParams().WithID(id).WithForce(new(viper.GetBool("force"))).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -246,7 +251,7 @@ func (c *switchCmd) Create(rq any) (*models.V1SwitchResponse, error) {
 }
 
 func (c *switchCmd) Update(rq *models.V1SwitchUpdateRequest) (*models.V1SwitchResponse, error) {
-	resp, err := c.client.SwitchOperations().UpdateSwitch(switch_operations.NewUpdateSwitchParams().WithBody(rq), nil)
+	resp, err := c.client.SwitchOperations().UpdateSwitch(switch_operations.NewUpdateSwitchParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -311,7 +316,7 @@ func (c *switchCmd) switchMachines() error {
 	var machines []*models.V1MachineIPMIResponse
 
 	if viper.IsSet("machine-id") {
-		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(viper.GetString("machine-id")), nil)
+		resp, err := c.client.Machine().FindIPMIMachine(machine.NewFindIPMIMachineParams().WithID(viper.GetString("machine-id")).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -323,7 +328,7 @@ func (c *switchCmd) switchMachines() error {
 			PartitionID: viper.GetString("partition"),
 			Rackid:      viper.GetString("rack"),
 			Sizeid:      viper.GetString("size"),
-		}), nil)
+		}).WithContext(c.ctx), nil)
 		if err != nil {
 			return err
 		}
@@ -389,7 +394,7 @@ func (c *switchCmd) switchMigrate(args []string) error {
 	resp, err := c.client.SwitchOperations().MigrateSwitch(switch_operations.NewMigrateSwitchParams().WithBody(&models.V1SwitchMigrateRequest{
 		OldSwitchID: new(args[0]),
 		NewSwitchID: new(args[1]),
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -462,7 +467,7 @@ func (c *switchCmd) describePort(args []string) error {
 		return fmt.Errorf("missing port")
 	}
 
-	resp, err := c.client.SwitchOperations().FindSwitch(switch_operations.NewFindSwitchParams().WithID(id), nil)
+	resp, err := c.client.SwitchOperations().FindSwitch(switch_operations.NewFindSwitchParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -483,7 +488,7 @@ func (c *switchCmd) togglePort(args []string, status string) error {
 	resp, err := c.client.SwitchOperations().ToggleSwitchPort(switch_operations.NewToggleSwitchPortParams().WithID(id).WithBody(&models.V1SwitchPortToggleRequest{
 		Nic:    &portid,
 		Status: &status,
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return err
 	}
@@ -169,7 +169,7 @@ ID  PARTITION  RACK    OS           METALCORE  IP       MODE         LAST SYNC
 			name: "list with filters",
 			cmd: func(want []*models.V1SwitchResponse) []string {
 				args := []string{"switch", "list", "--id", *want[0].ID, "--name", want[0].Name, "--os-vendor", want[0].Os.Vendor, "--os-version", want[0].Os.Version, "--partition", *want[0].Partition.ID, "--rack", *want[0].RackID}
-				assertExhaustiveArgs(t, args, "sort-by")
+				assertExhaustiveArgs(t, args, "sort-by", "watch", "interval")
 				return args
 			},
 			mocks: &client.MetalMockFns{
@@ -0,0 +1,286 @@
+package tableprinters
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+)
+
+// column is a named column of a table, which can be selected with --columns
+type column struct {
+	name   string
+	header string
+	values []string
+}
+
+// SetColumns selects and orders the columns of the printed tables by name. The columns flag only applies to the
+// main table, which is the first table printed, and takes precedence over the ones configured per table. Tables
+// without selected columns are printed as usual.
+func (t *TablePrinter) SetColumns(columns []string, tableColumns map[string][]string) {
+	t.columns = columns
+	t.tableColumns = tableColumns
+}
+
+// TableName returns the name of the table for the given data, which is used for configuring its columns
+func TableName(data any) string {
+	if _, ok := data.(*MachinesWithIPMI); ok {
+		return "machine"
+	}
+
+	typ := reflect.TypeOf(data)
+	for typ != nil && (typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice) {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return ""
+	}
+
+	name := strings.TrimSuffix(strings.TrimPrefix(typ.Name(), "V1"), "Response")
+
+	return kebabCase(name)
+}
+
+func (t *TablePrinter) selectedColumns(data any) []string {
+	name := TableName(data)
+	if t.mainTable == "" {
+		t.mainTable = name
+	}
+
+	if len(t.columns) > 0 && name == t.mainTable {
+		return t.columns
+	}
+	return t.tableColumns[name]
+}
+
+// machineIPMIColumns are the columns of the machine table, which are only available from the ipmi data of the machines
+var machineIPMIColumns = map[string]func(*models.V1MachineIPMI) string{
+	"BMC Address": func(ipmi *models.V1MachineIPMI) string {
+		return pointer.SafeDeref(ipmi.Address)
+	},
+	"Chassis Serial": func(ipmi *models.V1MachineIPMI) string {
+		return pointer.SafeDeref(ipmi.Fru).ChassisPartSerial
+	},
+	"Product Serial": func(ipmi *models.V1MachineIPMI) string {
+		return pointer.SafeDeref(ipmi.Fru).ProductSerial
+	},
+}
+
+// RequiresMachineIPMI returns true if one of the columns of the machine table needs the ipmi data of the machines,
+// which then have to be printed as MachinesWithIPMI
+func RequiresMachineIPMI(columns []string) bool {
+	for header := range machineIPMIColumns {
+		if slices.Contains(columns, columnName(header)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TablePrinter) selectColumns(data any, names []string) ([]string, [][]string, error) {
+	columns, err := t.availableColumns(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		header    []string
+		selected  []*column
+		available []string
+	)
+
+	for _, c := range columns {
+		available = append(available, c.name)
+	}
+
+	for _, name := range names {
+		idx := slices.IndexFunc(columns, func(c *column) bool {
+			return c.name == name
+		})
+		if idx < 0 {
+			return nil, nil, fmt.Errorf("unknown column %q for %s table, available columns are: %s", name, TableName(data), strings.Join(available, ", "))
+		}
+
+		header = append(header, columns[idx].header)
+		selected = append(selected, columns[idx])
+	}
+
+	var rows [][]string
+	if len(selected) > 0 {
+		for i := range selected[0].values {
+			var row []string
+			for _, c := range selected {
+				row = append(row, c.values[i])
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	return header, rows, nil
+}
+
+// availableColumns returns the columns of the table in normal and wide mode followed by the registered
+// columns, which are not part of any of these modes
+func (t *TablePrinter) availableColumns(data any) ([]*column, error) {
+	header, rows, err := t.toHeaderAndRows(data, false)
+	if err != nil {
+		return nil, err
+	}
+
+	wideHeader, wideRows, err := t.toHeaderAndRows(data, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var columns []*column
+
+	add := func(c *column) {
+		if len(c.values) != len(rows) {
+			return
+		}
+		if slices.ContainsFunc(columns, func(existing *column) bool { return existing.name == c.name }) {
+			return
+		}
+		columns = append(columns, c)
+	}
+
+	addAll := func(header []string, rows [][]string) {
+		for i, h := range header {
+			c := &column{name: columnName(h), header: h}
+			for _, row := range rows {
+				value := ""
+				if i < len(row) {
+					value = row[i]
+				}
+				c.values = append(c.values, value)
+			}
+			add(c)
+		}
+	}
+
+	addAll(header, rows)
+	// tables with a different row layout in wide mode only provide their normal columns
+	addAll(wideHeader, wideRows)
+
+	for _, c := range registeredColumns(data) {
+		add(c)
+	}
+
+	return columns, nil
+}
+
+// registeredColumns provides additional columns for tables, which are not shown in normal or wide mode
+func registeredColumns(data any) []*column {
+	switch d := data.(type) {
+	case *models.V1MachineResponse:
+		return registeredColumns(pointer.WrapInSlice(d))
+	case []*models.V1MachineResponse:
+		return newColumns(d, map[string]func(*models.V1MachineResponse) string{
+			"Liveliness": func(m *models.V1MachineResponse) string {
+				return pointer.SafeDeref(m.Liveliness)
+			},
+			"LED State": func(m *models.V1MachineResponse) string {
+				return pointer.SafeDeref(pointer.SafeDeref(m.Ledstate).Value)
+			},
+			"BIOS Version": func(m *models.V1MachineResponse) string {
+				return pointer.SafeDeref(pointer.SafeDeref(m.Bios).Version)
+			},
+		})
+	case *MachinesWithIPMI:
+		values := map[string]func(*models.V1MachineResponse) string{}
+		for header, value := range machineIPMIColumns {
+			values[header] = func(m *models.V1MachineResponse) string {
+				ipmi, ok := d.IPMI[pointer.SafeDeref(m.ID)]
+				if !ok {
+					return ""
+				}
+				return value(ipmi)
+			}
+		}
+
+		columns := append(registeredColumns(d.Machines), newColumns(d.Machines, values)...)
+		slices.SortFunc(columns, func(a, b *column) int {
+			return strings.Compare(a.name, b.name)
+		})
+
+		return columns
+	case *models.V1MachineIPMIResponse:
+		return registeredColumns(pointer.WrapInSlice(d))
+	case []*models.V1MachineIPMIResponse:
+		return newColumns(d, map[string]func(*models.V1MachineIPMIResponse) string{
+			"BMC Address": func(m *models.V1MachineIPMIResponse) string {
+				return pointer.SafeDeref(pointer.SafeDeref(m.Ipmi).Address)
+			},
+			"BMC User": func(m *models.V1MachineIPMIResponse) string {
+				return pointer.SafeDeref(pointer.SafeDeref(m.Ipmi).User)
+			},
+			"Hostname": func(m *models.V1MachineIPMIResponse) string {
+				return pointer.SafeDeref(pointer.SafeDeref(m.Allocation).Hostname)
+			},
+			"Project": func(m *models.V1MachineIPMIResponse) string {
+				return pointer.SafeDeref(pointer.SafeDeref(m.Allocation).Project)
+			},
+			"LED State": func(m *models.V1MachineIPMIResponse) string {
+				return pointer.SafeDeref(pointer.SafeDeref(m.Ledstate).Value)
+			},
+		})
+	default:
+		return nil
+	}
+}
+
+func newColumns[T any](data []T, values map[string]func(T) string) []*column {
+	var columns []*column
+	for header, value := range values {
+		c := &column{name: columnName(header), header: header}
+		for _, elem := range data {
+			c.values = append(c.values, value(elem))
+		}
+		columns = append(columns, c)
+	}
+
+	slices.SortFunc(columns, func(a, b *column) int {
+		return strings.Compare(a.name, b.name)
+	})
+
+	return columns
+}
+
+// columnName derives the name of a column from its header, the unnamed column of the status emojis is called status
+func columnName(header string) string {
+	if header == "" {
+		return "status"
+	}
+
+	var words []string
+	for word := range strings.FieldsFuncSeq(strings.ToLower(header), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words = append(words, word)
+	}
+
+	return strings.Join(words, "-")
+}
+
+// kebabCase converts a go type name like MachineIPMI into machine-ipmi
+func kebabCase(name string) string {
+	runes := []rune(name)
+
+	var result strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			previousLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if previousLower || nextLower {
+				result.WriteRune('-')
+			}
+		}
+		result.WriteRune(unicode.ToLower(r))
+	}
+
+	return result.String()
+}
@@ -0,0 +1,131 @@
+package tableprinters
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableName(t *testing.T) {
+	tests := []struct {
+		data any
+		want string
+	}{
+		{data: []*models.V1MachineResponse{}, want: "machine"},
+		{data: &models.V1MachineIPMIResponse{}, want: "machine-ipmi"},
+		{data: []*models.V1PartitionCapacity{}, want: "partition-capacity"},
+		{data: []*models.V1IPResponse{}, want: "ip"},
+		{data: []*SwitchDetail{}, want: "switch-detail"},
+		{data: &api.Contexts{}, want: "contexts"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			assert.Equal(t, tt.want, TableName(tt.data))
+		})
+	}
+}
+
+func TestTablePrinter_Columns(t *testing.T) {
+	machines := []*models.V1MachineResponse{
+		{
+			ID:         new("1"),
+			Rackid:     "rack-1",
+			Liveliness: new("Alive"),
+			Bios:       &models.V1MachineBIOS{Version: new("2.0")},
+			State:      &models.V1MachineState{Value: new(""), Description: new("")},
+			Events:     &models.V1MachineRecentProvisioningEvents{},
+			Allocation: &models.V1MachineAllocation{Hostname: new("host-1"), Name: new("machine-1")},
+		},
+		{
+			ID:         new("2"),
+			Rackid:     "rack-2",
+			Liveliness: new("Dead"),
+			State:      &models.V1MachineState{Value: new(""), Description: new("")},
+			Events:     &models.V1MachineRecentProvisioningEvents{},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		columns      []string
+		tableColumns map[string][]string
+		wantHeader   []string
+		wantRows     [][]string
+		wantErr      string
+	}{
+		{
+			name:       "normal, wide and registered columns",
+			columns:    []string{"rack", "name", "id", "bios-version", "liveliness"},
+			wantHeader: []string{"Rack", "Name", "ID", "BIOS Version", "Liveliness"},
+			wantRows: [][]string{
+				{"rack-1", "machine-1", "1", "2.0", "Alive"},
+				{"rack-2", "", "2", "", "Dead"},
+			},
+		},
+		{
+			name:         "columns configured for the table",
+			tableColumns: map[string][]string{"machine": {"id", "hostname"}, "switch": {"id"}},
+			wantHeader:   []string{"ID", "Hostname"},
+			wantRows: [][]string{
+				{"1", "host-1"},
+				{"2", ""},
+			},
+		},
+		{
+			name:         "columns flag takes precedence",
+			columns:      []string{"id"},
+			tableColumns: map[string][]string{"machine": {"id", "hostname"}},
+			wantHeader:   []string{"ID"},
+			wantRows:     [][]string{{"1"}, {"2"}},
+		},
+		{
+			name:    "unknown column",
+			columns: []string{"foo"},
+			wantErr: `unknown column "foo" for machine table, available columns are: id, status, last-event, when, age, hostname, project, size, image, partition, rack, description, name, ips, started, tags, lock-reserve, bios-version, led-state, liveliness`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := New()
+			tp.SetColumns(tt.columns, tt.tableColumns)
+
+			header, rows, err := tp.ToHeaderAndRows(machines, false)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			if diff := cmp.Diff(tt.wantHeader, header); diff != "" {
+				t.Errorf("header diff (+got -want):\n %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantRows, rows); diff != "" {
+				t.Errorf("rows diff (+got -want):\n %s", diff)
+			}
+		})
+	}
+}
+
+func TestTablePrinter_ColumnsOnlyForMainTable(t *testing.T) {
+	tp := New()
+	tp.SetColumns([]string{"time"}, map[string][]string{"machine-provisioning-event": {"event"}})
+
+	events := []*models.V1MachineProvisioningEvent{{Event: new("Phoned Home")}}
+
+	header, _, err := tp.ToHeaderAndRows(events, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Time"}, header)
+
+	// a secondary table is printed with its configured columns, the flag does not apply
+	header, _, err = tp.ToHeaderAndRows(&models.V1MachineIssue{ID: new("issue")}, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ID", "Severity", "Description", "Reference URL"}, header)
+
+	header, _, err = tp.ToHeaderAndRows(events, false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Time"}, header)
+}
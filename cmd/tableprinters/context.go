@@ -1,6 +1,11 @@
 package tableprinters
 
-import "github.com/metal-stack/metalctl/pkg/api"
+import (
+	"sort"
+	"strings"
+
+	"github.com/metal-stack/metalctl/pkg/api"
+)
 
 func (t *TablePrinter) ContextTable(data *api.Contexts, wide bool) ([]string, [][]string, error) {
 	var (
@@ -17,3 +22,25 @@ func (t *TablePrinter) ContextTable(data *api.Contexts, wide bool) ([]string, []
 
 	return header, rows, nil
 }
+
+func (t *TablePrinter) NamedContextTable(data *api.NamedContext, wide bool) ([]string, [][]string, error) {
+	var (
+		header   = []string{"Name", "API URL", "Issuer URL", "Issuer Type", "Client ID", "HMAC Auth Type", "Defaults"}
+		rows     [][]string
+		defaults []string
+	)
+
+	name := data.Name
+	if data.Current {
+		name = name + " [*]"
+	}
+
+	for flag, value := range data.Defaults {
+		defaults = append(defaults, flag+"="+value)
+	}
+	sort.Strings(defaults)
+
+	rows = append(rows, []string{name, data.ApiURL, data.IssuerURL, data.IssuerType, data.ClientID, data.HMACAuthType, strings.Join(defaults, "\n")})
+
+	return header, rows, nil
+}
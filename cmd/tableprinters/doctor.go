@@ -0,0 +1,42 @@
+package tableprinters
+
+import (
+	"github.com/fatih/color"
+)
+
+type DoctorCheckStatus string
+
+const (
+	DoctorCheckStatusPass DoctorCheckStatus = "pass"
+	DoctorCheckStatusWarn DoctorCheckStatus = "warn"
+	DoctorCheckStatusFail DoctorCheckStatus = "fail"
+)
+
+type DoctorCheck struct {
+	Name    string            `json:"name" yaml:"name"`
+	Status  DoctorCheckStatus `json:"status" yaml:"status"`
+	Message string            `json:"message" yaml:"message"`
+}
+
+func (t *TablePrinter) DoctorTable(data []*DoctorCheck, wide bool) ([]string, [][]string, error) {
+	var (
+		header = []string{"Check", "Status", "Message"}
+		rows   [][]string
+	)
+
+	for _, check := range data {
+		status := string(check.Status)
+		switch check.Status {
+		case DoctorCheckStatusPass:
+			status = color.GreenString(status)
+		case DoctorCheckStatusWarn:
+			status = color.YellowString(status)
+		case DoctorCheckStatusFail:
+			status = color.RedString(status)
+		}
+
+		rows = append(rows, []string{check.Name, status, check.Message})
+	}
+
+	return header, rows, nil
+}
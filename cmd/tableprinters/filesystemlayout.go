@@ -42,7 +42,7 @@ func (t *TablePrinter) FSLTable(data []*models.V1FilesystemLayoutResponse, wide
 		rows = append(rows, []string{pointer.SafeDeref(fsl.ID), fsl.Description, fss.String(), strings.Join(fsl.Constraints.Sizes, "\n"), strings.Join(imageConstraints, "\n")})
 	}
 
-	t.t.DisableAutoWrap(true)
+	t.disableAutoWrap()
 
 	return header, rows, nil
 }
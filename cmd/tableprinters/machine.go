@@ -2,10 +2,12 @@ package tableprinters
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/go-openapi/strfmt"
 	"github.com/metal-stack/metal-go/api/models"
 	"github.com/metal-stack/metal-lib/pkg/genericcli"
 	"github.com/metal-stack/metal-lib/pkg/pointer"
@@ -19,6 +21,12 @@ type MachinesAndIssues struct {
 	Issues           []*models.V1MachineIssue         `json:"issues" yaml:"issues"`
 }
 
+// MachinesWithIPMI are printed as machine table, which additionally provides the columns of the ipmi data.
+type MachinesWithIPMI struct {
+	Machines []*models.V1MachineResponse      `json:"machines" yaml:"machines"`
+	IPMI     map[string]*models.V1MachineIPMI `json:"ipmi" yaml:"ipmi"`
+}
+
 func (t *TablePrinter) MachineTable(data []*models.V1MachineResponse, wide bool) ([]string, [][]string, error) {
 	var (
 		rows [][]string
@@ -27,14 +35,13 @@ func (t *TablePrinter) MachineTable(data []*models.V1MachineResponse, wide bool)
 	header := []string{"ID", "", "Last Event", "When", "Age", "Hostname", "Project", "Size", "Image", "Partition", "Rack"}
 	if wide {
 		header = []string{"ID", "Last Event", "When", "Age", "Description", "Name", "Hostname", "Project", "Ips", "Size", "Image", "Partition", "Rack", "Started", "Tags", "Lock/Reserve"}
+		if t.statusColumn {
+			header = slices.Insert(header, 2, "Status")
+		}
 	}
 
 	for _, machine := range data {
-		machineID := *machine.ID
-		if machine.Ledstate != nil && machine.Ledstate.Value != nil && *machine.Ledstate.Value == "LED-ON" {
-			blue := color.New(color.FgBlue).SprintFunc()
-			machineID = blue(machineID)
-		}
+		machineID := t.machineID(*machine.ID, machine.Ledstate)
 
 		alloc := pointer.SafeDeref(machine.Allocation)
 		sizeID := pointer.SafeDeref(pointer.SafeDeref(machine.Size).ID)
@@ -79,10 +86,14 @@ func (t *TablePrinter) MachineTable(data []*models.V1MachineResponse, wide bool)
 			lastEvent = *machine.Events.Log[0].Event
 		}
 
-		emojis, _ := t.getMachineStatusEmojis(machine.Liveliness, machine.Events, machine.State, alloc.Vpn)
+		emojis, wideEmojis := t.getMachineStatusEmojis(machine.Liveliness, machine.Events, machine.State, alloc.Vpn, machine.Ledstate)
 
 		if wide {
-			rows = append(rows, []string{machineID, lastEvent, when, age, desc, name, hostname, project, ips, sizeID, image, partitionID, rack, started, tags, reserved})
+			row := []string{machineID, lastEvent, when, age, desc, name, hostname, project, ips, sizeID, image, partitionID, rack, started, tags, reserved}
+			if t.statusColumn {
+				row = slices.Insert(row, 2, wideEmojis)
+			}
+			rows = append(rows, row)
 		} else {
 			rows = append(rows, []string{machineID, emojis, lastEvent, when, age, truncatedHostname, project, sizeID, image, partitionID, rack})
 		}
@@ -91,10 +102,13 @@ func (t *TablePrinter) MachineTable(data []*models.V1MachineResponse, wide bool)
 	return header, rows, nil
 }
 
-func (t *TablePrinter) getMachineStatusEmojis(liveliness *string, events *models.V1MachineRecentProvisioningEvents, state *models.V1MachineState, vpn *models.V1MachineVPN) (string, string) {
+// getMachineStatusEmojis returns the status of a machine as emojis for the normal and as text for the wide table,
+// in plain mode both are given as status tokens
+func (t *TablePrinter) getMachineStatusEmojis(liveliness *string, events *models.V1MachineRecentProvisioningEvents, state *models.V1MachineState, vpn *models.V1MachineVPN, ledState *models.V1ChassisIdentifyLEDState) (string, string) {
 	var (
 		emojis []string
 		wide   []string
+		tokens []string
 	)
 
 	switch l := pointer.SafeDeref(liveliness); l {
@@ -103,12 +117,15 @@ func (t *TablePrinter) getMachineStatusEmojis(liveliness *string, events *models
 	case "Dead":
 		emojis = append(emojis, api.Skull)
 		wide = append(wide, l)
+		tokens = append(tokens, api.DeadToken)
 	case "Unknown":
 		emojis = append(emojis, api.Question)
 		wide = append(wide, l)
+		tokens = append(tokens, api.UnknownToken)
 	default:
 		emojis = append(emojis, api.Question)
 		wide = append(wide, l)
+		tokens = append(tokens, api.UnknownToken)
 	}
 
 	if state != nil {
@@ -118,9 +135,11 @@ func (t *TablePrinter) getMachineStatusEmojis(liveliness *string, events *models
 		case "LOCKED":
 			emojis = append(emojis, api.Lock)
 			wide = append(wide, "Locked")
+			tokens = append(tokens, api.LockedToken)
 		case "RESERVED":
 			emojis = append(emojis, api.Bark)
 			wide = append(wide, "Reserved")
+			tokens = append(tokens, api.ReservedToken)
 		}
 	}
 
@@ -128,27 +147,51 @@ func (t *TablePrinter) getMachineStatusEmojis(liveliness *string, events *models
 		if pointer.SafeDeref(events.FailedMachineReclaim) {
 			emojis = append(emojis, api.Ambulance)
 			wide = append(wide, "FailedReclaim")
+			tokens = append(tokens, api.FailedReclaimToken)
 		}
 
 		if events.LastErrorEvent != nil && time.Since(time.Time(events.LastErrorEvent.Time)) < t.lastEventErrorThreshold {
 			emojis = append(emojis, api.Exclamation)
 			wide = append(wide, "LastEventErrors")
+			tokens = append(tokens, api.LastEventErrorToken)
 		}
 
 		if pointer.SafeDeref(events.CrashLoop) {
 			emojis = append(emojis, api.Loop)
 			wide = append(wide, "CrashLoop")
+			tokens = append(tokens, api.CrashLoopToken)
 		}
 	}
 
 	if vpn != nil && *vpn.Connected {
 		emojis = append(emojis, api.VPN)
 		wide = append(wide, "VPN")
+		tokens = append(tokens, api.VPNToken)
+	}
+
+	if t.plain {
+		// the led state is shown by the color of the machine id otherwise
+		if isLEDOn(ledState) {
+			tokens = append(tokens, api.LEDOnToken)
+		}
+		return strings.Join(tokens, ","), strings.Join(tokens, ",")
 	}
 
 	return strings.Join(emojis, nbr), strings.Join(wide, ", ")
 }
 
+// machineID highlights the id of a machine with turned on LED, in plain mode the led state is a status token instead
+func (t *TablePrinter) machineID(id string, ledState *models.V1ChassisIdentifyLEDState) string {
+	if t.plain || !isLEDOn(ledState) {
+		return id
+	}
+	return color.New(color.FgBlue).Sprint(id)
+}
+
+func isLEDOn(ledState *models.V1ChassisIdentifyLEDState) bool {
+	return ledState != nil && pointer.SafeDeref(ledState.Value) == "LED-ON"
+}
+
 func (t *TablePrinter) MachineIPMITable(data []*models.V1MachineIPMIResponse, wide bool) ([]string, [][]string, error) {
 	var (
 		rows [][]string
@@ -160,15 +203,10 @@ func (t *TablePrinter) MachineIPMITable(data []*models.V1MachineIPMIResponse, wi
 	}
 
 	for _, machine := range data {
-		id := pointer.SafeDeref(machine.ID)
+		id := t.machineID(pointer.SafeDeref(machine.ID), machine.Ledstate)
 		partition := pointer.SafeDeref(pointer.SafeDeref(machine.Partition).ID)
 		size := pointer.SafeDeref(pointer.SafeDeref(machine.Size).ID)
 
-		if machine.Ledstate != nil && machine.Ledstate.Value != nil && *machine.Ledstate.Value == "LED-ON" {
-			blue := color.New(color.FgBlue).SprintFunc()
-			id = blue(id)
-		}
-
 		ipAddress := ""
 		mac := ""
 		bpn := ""
@@ -176,6 +214,9 @@ func (t *TablePrinter) MachineIPMITable(data []*models.V1MachineIPMIResponse, wi
 		ps := ""
 		bmcVersion := ""
 		power := color.WhiteString(dot)
+		if t.plain {
+			power = api.UnknownToken
+		}
 		powerText := ""
 		ipmi := machine.Ipmi
 		rack := machine.Rackid
@@ -193,7 +234,7 @@ func (t *TablePrinter) MachineIPMITable(data []*models.V1MachineIPMIResponse, wi
 				ps = fru.ProductSerial
 			}
 
-			power, powerText = extractPowerState(ipmi)
+			power, powerText = t.extractPowerState(ipmi)
 
 			if ipmi.LastUpdated != nil && !ipmi.LastUpdated.IsZero() {
 				lastUpdated = fmt.Sprintf("%s ago", humanizeDuration(time.Since(time.Time(*ipmi.LastUpdated))))
@@ -211,7 +252,7 @@ func (t *TablePrinter) MachineIPMITable(data []*models.V1MachineIPMIResponse, wi
 			lastEvent = *machine.Events.Log[0].Event
 		}
 
-		emojis, wideEmojis := t.getMachineStatusEmojis(machine.Liveliness, machine.Events, machine.State, nil)
+		emojis, wideEmojis := t.getMachineStatusEmojis(machine.Liveliness, machine.Events, machine.State, nil, machine.Ledstate)
 
 		if wide {
 			rows = append(rows, []string{id, lastEvent, wideEmojis, powerText, ipAddress, mac, bpn, cs, ps, biosVersion, bmcVersion, size, partition, rack, lastUpdated})
@@ -220,13 +261,16 @@ func (t *TablePrinter) MachineIPMITable(data []*models.V1MachineIPMIResponse, wi
 		}
 	}
 
-	t.t.DisableAutoWrap(true)
+	t.disableAutoWrap()
 
 	return header, rows, nil
 }
 
-func extractPowerState(ipmi *models.V1MachineIPMI) (short, wide string) {
+func (t *TablePrinter) extractPowerState(ipmi *models.V1MachineIPMI) (short, wide string) {
 	if ipmi == nil || ipmi.Powerstate == nil {
+		if t.plain {
+			return api.UnknownToken, wide
+		}
 		return color.WhiteString(poweron), wide
 	}
 
@@ -240,6 +284,11 @@ func extractPowerState(ipmi *models.V1MachineIPMI) (short, wide string) {
 		short = color.WhiteString(poweron)
 	}
 
+	tokens := []string{state}
+	if state == "" {
+		tokens = []string{api.UnknownToken}
+	}
+
 	wide = state
 	for _, ps := range ipmi.Powersupplies {
 		if ps.Status == nil || ps.Status.Health == nil {
@@ -248,15 +297,24 @@ func extractPowerState(ipmi *models.V1MachineIPMI) (short, wide string) {
 		if *ps.Status.Health != "OK" {
 			short = color.RedString(poweron)
 			wide = wide + nbr + "Power Supply" + nbr + *ps.Status.Health
+			tokens = append(tokens, "PSU-"+strings.ToUpper(*ps.Status.Health))
 		}
 		if ps.Status.State != nil && *ps.Status.State != "Enabled" {
 			short = color.RedString(powersleep)
 			wide = wide + nbr + *ps.Status.State
+			tokens = append(tokens, "PSU-"+strings.ToUpper(*ps.Status.State))
 		}
 	}
 
+	separator := nbr
+	if t.plain {
+		short = strings.Join(tokens, ",")
+		wide = strings.ReplaceAll(wide, nbr, " ")
+		separator = " "
+	}
+
 	if ipmi.Powermetric != nil {
-		short = fmt.Sprintf("%s"+nbr+"%.0fW", short, pointer.SafeDeref(ipmi.Powermetric.Averageconsumedwatts))
+		short = fmt.Sprintf("%s%s%.0fW", short, separator, pointer.SafeDeref(ipmi.Powermetric.Averageconsumedwatts))
 		wide = fmt.Sprintf("%s %.0fW", wide, pointer.SafeDeref(ipmi.Powermetric.Averageconsumedwatts))
 	}
 
@@ -281,7 +339,51 @@ func (t *TablePrinter) MachineLogsTable(data []*models.V1MachineProvisioningEven
 		rows = append(rows, []string{time.Time(i.Time).Format(time.RFC1123), pointer.SafeDeref(i.Event), msg})
 	}
 
-	t.t.DisableAutoWrap(true)
+	t.disableAutoWrap()
+
+	return header, rows, nil
+}
+
+// MachineEvent is a provisioning event of a machine in the timeline of machine events
+type MachineEvent struct {
+	MachineID string          `json:"machine_id" yaml:"machine_id"`
+	Partition string          `json:"partition" yaml:"partition"`
+	Rack      string          `json:"rack" yaml:"rack"`
+	Hostname  string          `json:"hostname,omitempty" yaml:"hostname,omitempty"`
+	Time      strfmt.DateTime `json:"time" yaml:"time"`
+	Event     string          `json:"event" yaml:"event"`
+	Message   string          `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+func (t *TablePrinter) MachineEventsTable(data []*MachineEvent, wide bool) ([]string, [][]string, error) {
+	var (
+		rows [][]string
+	)
+
+	header := []string{"Time", "Machine", "Rack", "Event", "Message"}
+	if wide {
+		header = []string{"Time", "Machine", "Partition", "Rack", "Hostname", "Event", "Message"}
+	}
+
+	for _, e := range data {
+		timestamp := time.Time(e.Time).Format(time.RFC3339)
+
+		if wide {
+			rows = append(rows, []string{timestamp, e.MachineID, e.Partition, e.Rack, e.Hostname, e.Event, e.Message})
+			continue
+		}
+
+		msg := e.Message
+		split := strings.Split(msg, "\n")
+		if len(split) > 1 {
+			msg = split[0] + " " + genericcli.TruncateEllipsis
+		}
+		msg = genericcli.TruncateEnd(msg, 120)
+
+		rows = append(rows, []string{timestamp, e.MachineID, e.Rack, e.Event, msg})
+	}
+
+	t.disableAutoWrap()
 
 	return header, rows, nil
 }
@@ -371,7 +473,7 @@ func (t *TablePrinter) MachineIssuesTable(data *MachinesAndIssues, wide bool) ([
 			lockDesc = genericcli.TruncateEnd(lockDescWide, 30)
 		}
 
-		power, powerText := extractPowerState(machine.Ipmi)
+		power, powerText := t.extractPowerState(machine.Ipmi)
 
 		when := ""
 		lastEvent := ""
@@ -381,7 +483,7 @@ func (t *TablePrinter) MachineIssuesTable(data *MachinesAndIssues, wide bool) ([
 			lastEvent = *machine.Events.Log[0].Event
 		}
 
-		emojis, _ := t.getMachineStatusEmojis(machine.Liveliness, machine.Events, machine.State, nil)
+		emojis, _ := t.getMachineStatusEmojis(machine.Liveliness, machine.Events, machine.State, nil, machine.Ledstate)
 
 		for i, id := range issue.Issues {
 			iss, ok := issuesByID[id]
@@ -410,7 +512,7 @@ func (t *TablePrinter) MachineIssuesTable(data *MachinesAndIssues, wide bool) ([
 		}
 	}
 
-	t.t.DisableAutoWrap(true)
+	t.disableAutoWrap()
 
 	return header, rows, nil
 }
@@ -446,7 +548,7 @@ func (t *TablePrinter) MachineIpmiChassisTable(data MachineIpmiChassisTable, wid
 
 		for i, m := range chassis.Machines {
 			var (
-				id        = pointer.SafeDeref(m.ID)
+				id        = t.machineID(pointer.SafeDeref(m.ID), m.Ledstate)
 				partition = pointer.SafeDeref(pointer.SafeDeref(m.Partition).ID)
 				size      = pointer.SafeDeref(pointer.SafeDeref(m.Size).ID)
 				ps        = ""
@@ -455,11 +557,6 @@ func (t *TablePrinter) MachineIpmiChassisTable(data MachineIpmiChassisTable, wid
 				hostname  = pointer.SafeDeref(pointer.SafeDeref(m.Allocation).Hostname)
 			)
 
-			if m.Ledstate != nil && m.Ledstate.Value != nil && *m.Ledstate.Value == "LED-ON" {
-				blue := color.New(color.FgBlue).SprintFunc()
-				id = blue(id)
-			}
-
 			if ipmi != nil {
 				fru := ipmi.Fru
 
@@ -468,7 +565,7 @@ func (t *TablePrinter) MachineIpmiChassisTable(data MachineIpmiChassisTable, wid
 				}
 			}
 
-			emojis, wideEmojis := t.getMachineStatusEmojis(m.Liveliness, m.Events, m.State, nil)
+			emojis, wideEmojis := t.getMachineStatusEmojis(m.Liveliness, m.Events, m.State, nil, m.Ledstate)
 
 			prefix := "├"
 			if i == len(chassis.Machines)-1 {
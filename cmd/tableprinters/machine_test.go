@@ -0,0 +1,174 @@
+package tableprinters
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/google/go-cmp/cmp"
+	"github.com/metal-stack/metal-go/api/models"
+)
+
+func Test_getMachineStatusEmojis(t *testing.T) {
+	tests := []struct {
+		name       string
+		plain      bool
+		liveliness *string
+		events     *models.V1MachineRecentProvisioningEvents
+		state      *models.V1MachineState
+		vpn        *models.V1MachineVPN
+		ledState   *models.V1ChassisIdentifyLEDState
+		want       string
+		wantWide   string
+	}{
+		{
+			name:       "alive machine",
+			liveliness: new("Alive"),
+			ledState:   &models.V1ChassisIdentifyLEDState{Value: new("LED-OFF")},
+			want:       "",
+			wantWide:   "",
+		},
+		{
+			name:       "emojis",
+			liveliness: new("Dead"),
+			events: &models.V1MachineRecentProvisioningEvents{
+				CrashLoop:            new(true),
+				FailedMachineReclaim: new(true),
+				LastErrorEvent:       &models.V1MachineProvisioningEvent{Time: strfmt.DateTime(time.Now())},
+			},
+			state:    &models.V1MachineState{Value: new("RESERVED")},
+			vpn:      &models.V1MachineVPN{Connected: new(true)},
+			ledState: &models.V1ChassisIdentifyLEDState{Value: new("LED-ON")},
+			want:     strings.Join([]string{"💀", "🚧", "🚑", "❗", "⭕", "🛡"}, nbr),
+			wantWide: "Dead, Reserved, FailedReclaim, LastEventErrors, CrashLoop, VPN",
+		},
+		{
+			name:       "plain",
+			plain:      true,
+			liveliness: new("Dead"),
+			events: &models.V1MachineRecentProvisioningEvents{
+				CrashLoop:            new(true),
+				FailedMachineReclaim: new(true),
+				LastErrorEvent:       &models.V1MachineProvisioningEvent{Time: strfmt.DateTime(time.Now())},
+			},
+			state:    &models.V1MachineState{Value: new("RESERVED")},
+			vpn:      &models.V1MachineVPN{Connected: new(true)},
+			ledState: &models.V1ChassisIdentifyLEDState{Value: new("LED-ON")},
+			want:     "DEAD,RESERVED,FAILED-RECLAIM,LAST-EVENT-ERROR,CRASHLOOP,VPN,LED-ON",
+			wantWide: "DEAD,RESERVED,FAILED-RECLAIM,LAST-EVENT-ERROR,CRASHLOOP,VPN,LED-ON",
+		},
+		{
+			name:       "plain locked machine in unknown condition",
+			plain:      true,
+			liveliness: new("Unknown"),
+			state:      &models.V1MachineState{Value: new("LOCKED")},
+			want:       "UNKNOWN,LOCKED",
+			wantWide:   "UNKNOWN,LOCKED",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.SetPlain(tt.plain)
+			p.SetLastEventErrorThreshold(time.Hour)
+
+			got, gotWide := p.getMachineStatusEmojis(tt.liveliness, tt.events, tt.state, tt.vpn, tt.ledState)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("diff (+got -want):\n %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantWide, gotWide); diff != "" {
+				t.Errorf("diff (+got -want):\n %s", diff)
+			}
+		})
+	}
+}
+
+func Test_extractPowerState(t *testing.T) {
+	tests := []struct {
+		name     string
+		ipmi     *models.V1MachineIPMI
+		want     string
+		wantWide string
+	}{
+		{
+			name: "no ipmi",
+			ipmi: nil,
+			want: "UNKNOWN",
+		},
+		{
+			name: "powered on",
+			ipmi: &models.V1MachineIPMI{
+				Powerstate:  new("ON"),
+				Powermetric: &models.V1PowerMetric{Averageconsumedwatts: new(float32(16))},
+			},
+			want:     "ON 16W",
+			wantWide: "ON 16W",
+		},
+		{
+			name: "broken power supply",
+			ipmi: &models.V1MachineIPMI{
+				Powerstate: new("OFF"),
+				Powersupplies: []*models.V1PowerSupply{
+					{Status: &models.V1PowerSupplyStatus{Health: new("Critical"), State: new("Absent")}},
+				},
+			},
+			want:     "OFF,PSU-CRITICAL,PSU-ABSENT",
+			wantWide: "OFF Power Supply Critical Absent",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := New()
+			p.SetPlain(true)
+
+			got, gotWide := p.extractPowerState(tt.ipmi)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("diff (+got -want):\n %s", diff)
+			}
+			if diff := cmp.Diff(tt.wantWide, gotWide); diff != "" {
+				t.Errorf("diff (+got -want):\n %s", diff)
+			}
+		})
+	}
+}
+
+func Test_MachineTable_WidePlainStatus(t *testing.T) {
+	machines := []*models.V1MachineResponse{
+		{
+			ID:         new("1"),
+			Liveliness: new("Alive"),
+			Ledstate:   &models.V1ChassisIdentifyLEDState{Value: new("LED-ON")},
+			State:      &models.V1MachineState{Value: new("LOCKED"), Description: new("maintenance")},
+			Events:     &models.V1MachineRecentProvisioningEvents{},
+		},
+	}
+
+	p := New()
+	p.SetPlain(true)
+	p.SetStatusColumn(true)
+
+	header, rows, err := p.MachineTable(machines, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff("Status", header[2]); diff != "" {
+		t.Errorf("diff (+got -want):\n %s", diff)
+	}
+	if diff := cmp.Diff("LOCKED,LED-ON", rows[0][2]); diff != "" {
+		t.Errorf("diff (+got -want):\n %s", diff)
+	}
+
+	p.SetStatusColumn(false)
+	header, _, err = p.MachineTable(machines, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.Contains(header, "Status") {
+		t.Errorf("the status column must only be added on request, got header %v", header)
+	}
+}
@@ -40,21 +40,21 @@ func (t *TablePrinter) NetworkTable(data []*models.V1NetworkResponse, wide bool)
 		}
 	}
 	for _, n := range *nn {
-		rows = append(rows, addNetwork("", n.parent, wide))
+		rows = append(rows, t.addNetwork("", n.parent, wide))
 		for i, c := range n.children {
 			prefix := "├"
 			if i == len(n.children)-1 {
 				prefix = "└"
 			}
 			prefix += "─╴"
-			rows = append(rows, addNetwork(prefix, c, wide))
+			rows = append(rows, t.addNetwork(prefix, c, wide))
 		}
 	}
 
 	return header, rows, nil
 }
 
-func addNetwork(prefix string, n *models.V1NetworkResponse, wide bool) []string {
+func (t *TablePrinter) addNetwork(prefix string, n *models.V1NetworkResponse, wide bool) []string {
 	id := fmt.Sprintf("%s%s", prefix, pointer.SafeDeref(n.ID))
 
 	prefixes := strings.Join(n.Prefixes, ",")
@@ -91,21 +91,8 @@ func addNetwork(prefix string, n *models.V1NetworkResponse, wide bool) []string
 			}
 		}
 
-		if ipv4Use >= 0.9 || ipv6Use >= 0.9 {
-			shortIPUsage = color.RedString(threequarterpie)
-		} else if ipv4Use >= 0.7 || ipv6Use >= 0.7 {
-			shortIPUsage += color.YellowString(halfpie)
-		} else {
-			shortIPUsage += color.GreenString(dot)
-		}
-
-		if ipv4PrefixUse >= 0.9 || ipv6PrefixUse >= 0.9 {
-			shortPrefixUsage = color.RedString(threequarterpie)
-		} else if ipv4PrefixUse >= 0.7 || ipv6PrefixUse >= 0.7 {
-			shortPrefixUsage = color.YellowString(halfpie)
-		} else {
-			shortPrefixUsage = color.GreenString(dot)
-		}
+		shortIPUsage = t.usageStatus(max(ipv4Use, ipv6Use))
+		shortPrefixUsage = t.usageStatus(max(ipv4PrefixUse, ipv6PrefixUse))
 	}
 
 	max := getMaxLineCount(n.Description, n.Name, n.Projectid, n.Partitionid, nat, prefixes, shortIPUsage, privateSuper)
@@ -130,6 +117,27 @@ func addNetwork(prefix string, n *models.V1NetworkResponse, wide bool) []string
 	}
 }
 
+// usageStatus returns the usage as colored pie or dot, in plain mode as status token
+func (t *TablePrinter) usageStatus(use float64) string {
+	switch {
+	case use >= 0.9:
+		if t.plain {
+			return "FULL"
+		}
+		return color.RedString(threequarterpie)
+	case use >= 0.7:
+		if t.plain {
+			return "HIGH"
+		}
+		return color.YellowString(halfpie)
+	default:
+		if t.plain {
+			return "OK"
+		}
+		return color.GreenString(dot)
+	}
+}
+
 func (nn *networks) appendChild(parentID string, child *models.V1NetworkResponse) bool {
 	for _, n := range *nn {
 		if *n.parent.ID == parentID {
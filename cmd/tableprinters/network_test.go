@@ -0,0 +1,53 @@
+package tableprinters
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/metal-stack/metal-go/api/models"
+)
+
+func Test_NetworkTable_Plain(t *testing.T) {
+	p := New()
+	p.SetPlain(true)
+
+	_, rows, err := p.NetworkTable([]*models.V1NetworkResponse{
+		{
+			ID:  new("nw1"),
+			Nat: new(false),
+			Consumption: &models.V1NetworkConsumption{
+				IPV4: &models.V1NetworkUsage{
+					AvailableIps:      new(int64(100)),
+					UsedIps:           new(int64(95)),
+					AvailablePrefixes: new(int64(10)),
+					UsedPrefixes:      new(int64(7)),
+				},
+			},
+		},
+		{
+			ID:  new("nw2"),
+			Nat: new(false),
+			Consumption: &models.V1NetworkConsumption{
+				IPV4: &models.V1NetworkUsage{
+					AvailableIps:      new(int64(100)),
+					UsedIps:           new(int64(1)),
+					AvailablePrefixes: new(int64(0)),
+					UsedPrefixes:      new(int64(0)),
+				},
+			},
+		},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got [][]string
+	for _, row := range rows {
+		// prefix usage and ip usage
+		got = append(got, []string{row[6], row[8]})
+	}
+
+	if diff := cmp.Diff([][]string{{"HIGH", "FULL"}, {"OK", "OK"}}, got); diff != "" {
+		t.Errorf("diff (+got -want):\n %s", diff)
+	}
+}
@@ -14,6 +14,11 @@ type TablePrinter struct {
 	t                       *printers.TablePrinter
 	lastEventErrorThreshold time.Duration
 	markdown                bool
+	plain                   bool
+	statusColumn            bool
+	columns                 []string
+	tableColumns            map[string][]string
+	mainTable               string
 }
 
 func New() *TablePrinter {
@@ -32,7 +37,32 @@ func (t *TablePrinter) SetMarkdown(markdown bool) {
 	t.markdown = markdown
 }
 
+// SetPlain replaces emojis, symbols and colors with textual status tokens
+func (t *TablePrinter) SetPlain(plain bool) {
+	t.plain = plain
+}
+
+// SetStatusColumn adds a column with the textual status tokens to wide tables, which otherwise only show the status with colors
+func (t *TablePrinter) SetStatusColumn(statusColumn bool) {
+	t.statusColumn = statusColumn
+}
+
+// disableAutoWrap prevents wrapping of long cells, the printer is not set when the rows are printed as csv
+func (t *TablePrinter) disableAutoWrap() {
+	if t.t != nil {
+		t.t.DisableAutoWrap(true)
+	}
+}
+
 func (t *TablePrinter) ToHeaderAndRows(data any, wide bool) ([]string, [][]string, error) {
+	if columns := t.selectedColumns(data); len(columns) > 0 {
+		return t.selectColumns(data, columns)
+	}
+
+	return t.toHeaderAndRows(data, wide)
+}
+
+func (t *TablePrinter) toHeaderAndRows(data any, wide bool) ([]string, [][]string, error) {
 	switch d := data.(type) {
 	case []*models.V1AuditResponse:
 		return t.AuditTable(d, wide)
@@ -42,6 +72,10 @@ func (t *TablePrinter) ToHeaderAndRows(data any, wide bool) ([]string, [][]strin
 		return t.MachineTable(d, wide)
 	case *models.V1MachineResponse:
 		return t.MachineTable(pointer.WrapInSlice(d), wide)
+	case *MachinesWithIPMI:
+		return t.MachineTable(d.Machines, wide)
+	case []*MachineEvent:
+		return t.MachineEventsTable(d, wide)
 	case *MachinesAndIssues:
 		return t.MachineIssuesTable(d, wide)
 	case []*models.V1MachineIssue:
@@ -104,6 +138,12 @@ func (t *TablePrinter) ToHeaderAndRows(data any, wide bool) ([]string, [][]strin
 		return t.FSLTable(d, wide)
 	case *api.Contexts:
 		return t.ContextTable(d, wide)
+	case *api.NamedContext:
+		return t.NamedContextTable(d, wide)
+	case []*DoctorCheck:
+		return t.DoctorTable(d, wide)
+	case *api.WhoAmI:
+		return t.WhoAmITable(d, wide)
 
 	case *models.V1SizeImageConstraintResponse:
 		return t.SizeImageConstraintTable(pointer.WrapInSlice(d), wide)
@@ -49,7 +49,7 @@ func (t *TablePrinter) SizeTable(data []*models.V1SizeResponse, wide bool) ([]st
 		rows = append(rows, row)
 	}
 
-	t.t.DisableAutoWrap(true)
+	t.disableAutoWrap()
 
 	return header, rows, nil
 }
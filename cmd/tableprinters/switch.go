@@ -23,7 +23,7 @@ func (t *TablePrinter) SwitchTable(data []*models.V1SwitchResponse, wide bool) (
 	if wide {
 		header = []string{"ID", "Partition", "Rack", "OS", "Metalcore", "IP", "Mode", "Last Sync", "Sync Duration", "Last Error"}
 
-		t.t.DisableAutoWrap(true)
+		t.disableAutoWrap()
 	}
 
 	for _, s := range data {
@@ -40,6 +40,10 @@ func (t *TablePrinter) SwitchTable(data []*models.V1SwitchResponse, wide bool) (
 			allup       = true
 		)
 
+		if t.plain {
+			shortStatus = ""
+		}
+
 		nicmap := make(map[string]*models.V1SwitchNic)
 		for _, n := range s.Nics {
 			if n.Name != nil {
@@ -66,13 +70,13 @@ func (t *TablePrinter) SwitchTable(data []*models.V1SwitchResponse, wide bool) (
 			syncDur := time.Duration(*s.LastSync.Duration).Round(time.Millisecond)
 
 			if syncAge >= time.Minute*10 || syncDur >= 30*time.Second {
-				shortStatus = color.RedString(dot)
+				shortStatus = t.switchStatus(color.RedString, "ERROR")
 			} else if syncAge >= time.Minute*1 || syncDur >= 20*time.Second {
-				shortStatus = color.YellowString(dot)
+				shortStatus = t.switchStatus(color.YellowString, "WARNING")
 			} else {
-				shortStatus = color.GreenString(dot)
+				shortStatus = t.switchStatus(color.GreenString, "OK")
 				if !allup {
-					shortStatus = color.YellowString(dot)
+					shortStatus = t.switchStatus(color.YellowString, "WARNING")
 				}
 			}
 
@@ -87,7 +91,7 @@ func (t *TablePrinter) SwitchTable(data []*models.V1SwitchResponse, wide bool) (
 				lastError = fmt.Sprintf("%s ago: %s", humanizeDuration(time.Since(errorTime)), s.LastSyncError.Error)
 
 				if errorTime.After(time.Time(pointer.SafeDeref(pointer.SafeDeref(s.LastSync).Time))) {
-					shortStatus = color.RedString(dot)
+					shortStatus = t.switchStatus(color.RedString, "ERROR")
 				}
 			}
 		}
@@ -96,6 +100,9 @@ func (t *TablePrinter) SwitchTable(data []*models.V1SwitchResponse, wide bool) (
 		switch s.Mode {
 		case "replace":
 			shortStatus = nbr + color.RedString(dot)
+			if t.plain {
+				shortStatus = "REPLACE"
+			}
 			mode = "replace"
 		default:
 			mode = "operational"
@@ -113,6 +120,9 @@ func (t *TablePrinter) SwitchTable(data []*models.V1SwitchResponse, wide bool) (
 			default:
 				osIcon = s.Os.Vendor
 			}
+			if t.plain {
+				osIcon = s.Os.Vendor
+			}
 
 			os = s.Os.Vendor
 			if s.Os.Version != "" {
@@ -132,6 +142,14 @@ func (t *TablePrinter) SwitchTable(data []*models.V1SwitchResponse, wide bool) (
 	return header, rows, nil
 }
 
+// switchStatus returns the colored status dot of a switch or the given status token in plain mode
+func (t *TablePrinter) switchStatus(colorize func(format string, a ...any) string, token string) string {
+	if t.plain {
+		return token
+	}
+	return colorize(dot)
+}
+
 type SwitchesWithMachines struct {
 	SS []*models.V1SwitchResponse               `json:"switches" yaml:"switches"`
 	MS map[string]*models.V1MachineIPMIResponse `json:"machines" yaml:"machines"`
@@ -147,7 +165,7 @@ func (t *TablePrinter) SwitchWithConnectedMachinesTable(data *SwitchesWithMachin
 		header = []string{"ID", "", "NIC Name", "Identifier", "Partition", "Rack", "Size", "Hostname", "Product Serial", "Chassis Serial"}
 	}
 
-	t.t.DisableAutoWrap(true)
+	t.disableAutoWrap()
 
 	for _, s := range data.SS {
 		id := pointer.SafeDeref(s.ID)
@@ -211,7 +229,10 @@ func (t *TablePrinter) SwitchWithConnectedMachinesTable(data *SwitchesWithMachin
 			nicstate := pointer.SafeDeref(nic.Actual)
 			bgpstate := pointer.SafeDeref(nic.BgpPortState)
 			if nicstate != "UP" {
-				nicname = fmt.Sprintf("%s (%s)", nicname, color.RedString(nicstate))
+				if !t.plain {
+					nicstate = color.RedString(nicstate)
+				}
+				nicname = fmt.Sprintf("%s (%s)", nicname, nicstate)
 			}
 			if bgpstate.BgpState != nil && wide {
 				switch *bgpstate.BgpState {
@@ -224,7 +245,7 @@ func (t *TablePrinter) SwitchWithConnectedMachinesTable(data *SwitchesWithMachin
 			}
 
 			if wide {
-				emojis, _ := t.getMachineStatusEmojis(m.Liveliness, m.Events, m.State, pointer.SafeDeref(m.Allocation).Vpn)
+				emojis, _ := t.getMachineStatusEmojis(m.Liveliness, m.Events, m.State, pointer.SafeDeref(m.Allocation).Vpn, m.Ledstate)
 
 				rows = append(rows, []string{
 					fmt.Sprintf("%s%s", prefix, pointer.SafeDeref(m.ID)),
@@ -0,0 +1,28 @@
+package tableprinters
+
+import (
+	"strings"
+	"time"
+
+	"github.com/metal-stack/metalctl/pkg/api"
+)
+
+func (t *TablePrinter) WhoAmITable(data *api.WhoAmI, wide bool) ([]string, [][]string, error) {
+	var (
+		header = []string{"User", "Tenant", "Context", "Expires In"}
+		rows   [][]string
+	)
+
+	if wide {
+		header = []string{"User", "Tenant", "Context", "Expires In", "Expires At", "Issuer", "API URL", "Groups"}
+	}
+
+	row := []string{data.User, data.Tenant, data.Context, data.RemainingValidity}
+	if wide {
+		row = append(row, data.ExpiresAt.Format(time.RFC3339), data.Issuer, data.APIURL, strings.Join(data.Groups, "\n"))
+	}
+
+	rows = append(rows, row)
+
+	return header, rows, nil
+}
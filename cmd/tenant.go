@@ -58,7 +58,7 @@ func newTenantCmd(c *config) *cobra.Command {
 }
 
 func (c *tenantCmd) Get(id string) (*models.V1TenantResponse, error) {
-	resp, err := c.client.Tenant().GetTenant(tenantmodel.NewGetTenantParams().WithID(id), nil)
+	resp, err := c.client.Tenant().GetTenant(tenantmodel.NewGetTenantParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -80,7 +80,7 @@ func (c *tenantCmd) List() ([]*models.V1TenantResponse, error) {
 		ID:          viper.GetString("id"),
 		Name:        viper.GetString("name"),
 		Annotations: annotations,
-	}), nil)
+	}).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -89,7 +89,7 @@ func (c *tenantCmd) List() ([]*models.V1TenantResponse, error) {
 }
 
 func (c *tenantCmd) Delete(id string) (*models.V1TenantResponse, error) {
-	resp, err := c.client.Tenant().DeleteTenant(tenantmodel.NewDeleteTenantParams().WithID(id), nil)
+	resp, err := c.client.Tenant().DeleteTenant(tenantmodel.NewDeleteTenantParams().WithID(id).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -98,7 +98,7 @@ func (c *tenantCmd) Delete(id string) (*models.V1TenantResponse, error) {
 }
 
 func (c *tenantCmd) Create(rq *models.V1TenantCreateRequest) (*models.V1TenantResponse, error) {
-	resp, err := c.client.Tenant().CreateTenant(tenantmodel.NewCreateTenantParams().WithBody(rq), nil)
+	resp, err := c.client.Tenant().CreateTenant(tenantmodel.NewCreateTenantParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		var r *tenantmodel.CreateTenantConflict
 		if errors.As(err, &r) {
@@ -122,7 +122,7 @@ func (c *tenantCmd) Update(rq *models.V1TenantUpdateRequest) (*models.V1TenantRe
 
 	rq.Meta.Version = getResp.Meta.Version
 
-	updateResp, err := c.client.Tenant().UpdateTenant(tenantmodel.NewUpdateTenantParams().WithBody(rq), nil)
+	updateResp, err := c.client.Tenant().UpdateTenant(tenantmodel.NewUpdateTenantParams().WithBody(rq).WithContext(c.ctx), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/metal-stack/v"
+)
+
+// redactedHeaders contain credentials, which must not show up in traces
+var redactedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Data-Salt"}
+
+// traceTransport logs every http round trip and optionally records it for a har archive
+type traceTransport struct {
+	next     http.RoundTripper
+	log      *slog.Logger
+	recorder *harRecorder
+}
+
+func (t *traceTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	var requestBody []byte
+	if r.Body != nil {
+		err := makeBodyReplayable(r)
+		if err != nil {
+			return nil, err
+		}
+		body, err := r.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		requestBody, err = io.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(r)
+	latency := time.Since(start)
+
+	if err != nil {
+		t.log.Info("http request failed", "method", r.Method, "url", r.URL.String(), "latency", latency.String(), "request_size", len(requestBody), "request_headers", redactHeaders(r.Header), "error", err)
+		t.recorder.record(start, latency, r, requestBody, nil, nil, err)
+		return nil, err
+	}
+
+	responseBody, readErr := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	t.log.Info("http request", "method", r.Method, "url", r.URL.String(), "status", resp.StatusCode, "latency", latency.String(), "request_size", len(requestBody), "response_size", len(responseBody),
+		"request_headers", redactHeaders(r.Header), "response_headers", redactHeaders(resp.Header))
+	t.recorder.record(start, latency, r, requestBody, resp, responseBody, readErr)
+
+	if readErr != nil {
+		return nil, readErr
+	}
+
+	return resp, nil
+}
+
+// harRecorder collects the round trips as entries of a http archive, see http://www.softwareishard.com/blog/har-12-spec/
+type harRecorder struct {
+	mu      sync.Mutex
+	entries []harEntry
+}
+
+type harLog struct {
+	Log struct {
+		Version string     `json:"version"`
+		Creator harCreator `json:"creator"`
+		Entries []harEntry `json:"entries"`
+	} `json:"log"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Error           string      `json:"_error,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func (h *harRecorder) record(start time.Time, latency time.Duration, r *http.Request, requestBody []byte, resp *http.Response, responseBody []byte, err error) {
+	if h == nil {
+		return
+	}
+
+	millis := float64(latency.Microseconds()) / 1000
+
+	entry := harEntry{
+		StartedDateTime: start,
+		Time:            millis,
+		Request: harRequest{
+			Method:      r.Method,
+			URL:         r.URL.String(),
+			HTTPVersion: r.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(r.Header),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(requestBody),
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    -1,
+		},
+		Timings: harTimings{Wait: millis},
+	}
+
+	query := r.URL.Query()
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		for _, value := range query[name] {
+			entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: value})
+		}
+	}
+	if len(requestBody) > 0 {
+		entry.Request.PostData = &harPostData{MimeType: r.Header.Get("Content-Type"), Text: string(requestBody)}
+	}
+	if resp != nil {
+		entry.Response.Status = resp.StatusCode
+		entry.Response.StatusText = http.StatusText(resp.StatusCode)
+		entry.Response.HTTPVersion = resp.Proto
+		entry.Response.Headers = harHeaders(resp.Header)
+		entry.Response.BodySize = len(responseBody)
+		entry.Response.Content = harContent{
+			Size:     len(responseBody),
+			MimeType: resp.Header.Get("Content-Type"),
+			Text:     string(responseBody),
+		}
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, entry)
+}
+
+// write stores the recorded entries as har archive in the given file
+func (h *harRecorder) write(path string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var archive harLog
+	archive.Log.Version = "1.2"
+	archive.Log.Creator = harCreator{Name: binaryName, Version: v.V.String()}
+	archive.Log.Entries = h.entries
+	if archive.Log.Entries == nil {
+		archive.Log.Entries = []harEntry{}
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(archive)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	header = redactHeaders(header)
+
+	result := []harNameValue{}
+	for _, name := range slices.Sorted(maps.Keys(header)) {
+		for _, value := range header[name] {
+			result = append(result, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	return result
+}
+
+// redactHeaders returns a copy of the headers with credentials being hidden
+func redactHeaders(header http.Header) http.Header {
+	result := header.Clone()
+	for _, name := range redactedHeaders {
+		if result.Get(name) != "" {
+			result.Set(name, "<redacted>")
+		}
+	}
+	return result
+}
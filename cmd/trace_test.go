@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/metal-stack/metal-go/api/client/ip"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_traceTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"ipaddress":"1.2.3.4"}]`))
+	}))
+	defer server.Close()
+
+	var (
+		logs     bytes.Buffer
+		recorder = &harRecorder{}
+	)
+
+	client, err := newMetalClient(server.URL, &traceTransport{
+		next:     http.DefaultTransport,
+		log:      slog.New(slog.NewJSONHandler(&logs, nil)),
+		recorder: recorder,
+	}, hmacAuthenticator("Metal-Admin", "secret-hmac"))
+	require.NoError(t, err)
+
+	_, err = client.IP().FindIPs(ip.NewFindIPsParams().WithBody(&models.V1IPFindRequest{Projectid: "p"}), nil)
+	require.NoError(t, err)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+	assert.Equal(t, "http request", line["msg"])
+	assert.Equal(t, "POST", line["method"])
+	assert.Equal(t, server.URL+"/v1/ip/find", line["url"])
+	assert.InDelta(t, 200, line["status"], 0)
+	assert.InDelta(t, 25, line["response_size"], 0)
+	assert.NotContains(t, logs.String(), "Metal-Admin ")
+
+	path := filepath.Join(t.TempDir(), "trace.har")
+	require.NoError(t, recorder.write(path))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var archive harLog
+	require.NoError(t, json.Unmarshal(raw, &archive))
+	assert.Equal(t, "1.2", archive.Log.Version)
+	assert.Equal(t, binaryName, archive.Log.Creator.Name)
+	require.Len(t, archive.Log.Entries, 1)
+
+	entry := archive.Log.Entries[0]
+	assert.Equal(t, "POST", entry.Request.Method)
+	assert.Equal(t, server.URL+"/v1/ip/find", entry.Request.URL)
+	assert.JSONEq(t, `{"projectid":"p","tags":null}`, entry.Request.PostData.Text)
+	assert.Contains(t, entry.Request.Headers, harNameValue{Name: "Authorization", Value: "<redacted>"})
+	assert.Contains(t, entry.Request.Headers, harNameValue{Name: "X-Data-Salt", Value: "<redacted>"})
+	assert.Equal(t, 200, entry.Response.Status)
+	assert.Equal(t, `[{"ipaddress":"1.2.3.4"}]`, entry.Response.Content.Text)
+	assert.NotContains(t, string(raw), "Metal-Admin ")
+}
@@ -64,7 +64,7 @@ func newUpdateCmd(c *config) *cobra.Command {
 }
 
 func getMinimumClientVersion(c *config) (*string, error) {
-	resp, err := c.client.Version().Info(version.NewInfoParams(), clientNoAuth())
+	resp, err := c.client.Version().Info(version.NewInfoParams().WithContext(c.ctx), clientNoAuth())
 	if err != nil {
 		return nil, err
 	}
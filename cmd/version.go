@@ -18,7 +18,7 @@ func newVersionCmd(c *config) *cobra.Command {
 				Client: v.V.String(),
 			}
 
-			resp, err := c.client.Version().Info(version.NewInfoParams(), nil)
+			resp, err := c.client.Version().Info(version.NewInfoParams().WithContext(c.ctx), nil)
 			if err == nil {
 				v.Server = resp.Payload
 			}
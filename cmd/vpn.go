@@ -46,7 +46,7 @@ func (c *config) vpnAuthKeyCreate() error {
 				Pid:       new(viper.GetString("project")),
 				Ephemeral: pointer.PointerOrNil(viper.GetBool("ephemeral")),
 				Reason:    new(viper.GetString("reason")),
-			}), nil,
+			}).WithContext(c.ctx), nil,
 	)
 	if err != nil {
 		return err
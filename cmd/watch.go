@@ -0,0 +1,243 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mattn/go-isatty"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// event types of the ndjson output in watch mode
+const (
+	watchEventAdded    = "added"
+	watchEventModified = "modified"
+	watchEventDeleted  = "deleted"
+)
+
+// clearScreen moves the cursor to the top left corner and clears the terminal
+const clearScreen = "\033[H\033[2J"
+
+// addWatchFlags adds --watch and --interval to a list command, which then repeats the listing until it gets interrupted
+func (c *config) addWatchFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("watch", false, "repeat the listing in the given interval and highlight changed rows, with -o json changes are printed as ndjson events.")
+	cmd.Flags().Duration("interval", 5*time.Second, "the interval for polling the metal-api in watch mode.")
+
+	runE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if !viper.GetBool("watch") {
+			return runE(cmd, args)
+		}
+
+		return c.watch(cmd.CommandPath(), func() error {
+			return runE(cmd, args)
+		})
+	}
+}
+
+// watch calls list in the configured interval, the list printer is replaced such that every listing
+// redraws the previous one in place or prints the changes as events
+func (c *config) watch(command string, list func() error) error {
+	interval := viper.GetDuration("interval")
+	if interval <= 0 {
+		return &usageError{err: fmt.Errorf("interval must be greater than zero")}
+	}
+
+	c.listPrinter = &watchPrinter{
+		out:          c.out,
+		redraw:       isTerminal(c.out),
+		events:       viper.GetString("output-format") == "json",
+		header:       fmt.Sprintf("Every %s: %s", interval, command),
+		tableColumns: c.tableColumns,
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		err := list()
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			if !isTransientWatchError(err) {
+				return err
+			}
+			// a single failing poll should not end the watch
+			c.log.Error("unable to list entities", "error", err)
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// isTransientWatchError returns true for errors which may vanish with the next poll, like network errors
+// or an unavailable metal-api, all other errors like missing permissions would just repeat
+func isTransientWatchError(err error) bool {
+	cliErr := toCLIError(err, "")
+	switch {
+	case cliErr.Code == exitCodeNetwork, cliErr.Code == exitCodeTimeout, cliErr.Code == exitCodeServerError:
+		return true
+	case cliErr.HTTPStatus == http.StatusTooManyRequests:
+		return true
+	default:
+		return isTransientNetworkError(err)
+	}
+}
+
+// isTerminal returns true if the writer is connected to a terminal
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(interface{ Fd() uintptr })
+	if !ok {
+		return false
+	}
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// watchPrinter prints the listings of the watch mode, either by redrawing the output of the configured printer
+// with highlighted changes or as ndjson events describing the changes since the last listing.
+// If the output is not a terminal, the listings are appended instead of redrawn.
+type watchPrinter struct {
+	out          io.Writer
+	redraw       bool
+	events       bool
+	header       string
+	tableColumns map[string][]string
+
+	polls         int
+	previousRows  map[string]bool
+	previousItems map[string]string
+}
+
+type watchEvent struct {
+	Type   string `json:"type"`
+	Object any    `json:"object"`
+}
+
+func (p *watchPrinter) Print(data any) error {
+	defer func() {
+		p.polls++
+	}()
+
+	if p.events {
+		return p.printEvents(data)
+	}
+
+	var buf bytes.Buffer
+	err := newPrinterFromCLI(&buf, p.tableColumns).Print(data)
+	if err != nil {
+		return err
+	}
+
+	var (
+		rows   = map[string]bool{}
+		result strings.Builder
+	)
+
+	if p.redraw {
+		result.WriteString(clearScreen)
+	} else if p.polls > 0 {
+		result.WriteString("\n")
+	}
+	fmt.Fprintf(&result, "%s    %s\n\n", p.header, time.Now().Format(time.DateTime))
+
+	for line := range strings.Lines(buf.String()) {
+		line = strings.TrimSuffix(line, "\n")
+
+		// the column widths of tables change with the content, so only the values of a row are compared
+		row := strings.Join(strings.Fields(line), " ")
+		rows[row] = true
+
+		if p.polls > 0 && row != "" && !p.previousRows[row] {
+			line = color.New(color.ReverseVideo).Sprint(line)
+		}
+
+		result.WriteString(line + "\n")
+	}
+
+	p.previousRows = rows
+
+	_, err = io.WriteString(p.out, result.String())
+	return err
+}
+
+func (p *watchPrinter) printEvents(data any) error {
+	elems, err := toJSONElements(data)
+	if err != nil {
+		return err
+	}
+
+	var (
+		encoder = json.NewEncoder(p.out)
+		items   = map[string]string{}
+	)
+
+	for _, elem := range elems {
+		raw, err := json.Marshal(elem)
+		if err != nil {
+			return err
+		}
+
+		key := watchKey(elem, string(raw))
+		items[key] = string(raw)
+
+		previous, ok := p.previousItems[key]
+		switch {
+		case !ok:
+			err = encoder.Encode(watchEvent{Type: watchEventAdded, Object: elem})
+		case previous != string(raw):
+			err = encoder.Encode(watchEvent{Type: watchEventModified, Object: elem})
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(p.previousItems)) {
+		if _, ok := items[key]; ok {
+			continue
+		}
+
+		var object any
+		err = json.Unmarshal([]byte(p.previousItems[key]), &object)
+		if err != nil {
+			return err
+		}
+
+		err = encoder.Encode(watchEvent{Type: watchEventDeleted, Object: object})
+		if err != nil {
+			return err
+		}
+	}
+
+	p.previousItems = items
+
+	return nil
+}
+
+// watchKey identifies an entity across listings, entities without an id are identified by their content
+func watchKey(elem any, raw string) string {
+	if m, ok := elem.(map[string]any); ok {
+		for _, field := range []string{"id", "ipaddress"} {
+			if id, ok := m[field].(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+	return raw
+}
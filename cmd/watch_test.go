@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/metal-stack/metal-go/api/client/machine"
+	"github.com/metal-stack/metal-go/api/models"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_watchPrinter_events(t *testing.T) {
+	var out bytes.Buffer
+	p := &watchPrinter{out: &out, events: true}
+
+	require.NoError(t, p.Print([]*models.V1IPResponse{
+		{Ipaddress: new("1.1.1.1"), Name: "a"},
+		{Ipaddress: new("1.1.1.2"), Name: "b"},
+	}))
+	require.NoError(t, p.Print([]*models.V1IPResponse{
+		{Ipaddress: new("1.1.1.1"), Name: "a"},
+		{Ipaddress: new("1.1.1.3"), Name: "c"},
+	}))
+	require.NoError(t, p.Print([]*models.V1IPResponse{
+		{Ipaddress: new("1.1.1.1"), Name: "changed"},
+		{Ipaddress: new("1.1.1.3"), Name: "c"},
+	}))
+
+	var got []string
+	for line := range strings.Lines(out.String()) {
+		var event watchEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		got = append(got, event.Type+" "+event.Object.(map[string]any)["ipaddress"].(string)+" "+event.Object.(map[string]any)["name"].(string))
+	}
+
+	assert.Equal(t, []string{
+		"added 1.1.1.1 a",
+		"added 1.1.1.2 b",
+		"added 1.1.1.3 c",
+		"deleted 1.1.1.2 b",
+		"modified 1.1.1.1 changed",
+	}, got)
+}
+
+func Test_watchPrinter_highlightsChangedRows(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("output-format", "template")
+	viper.Set("template", "{{ .id }} {{ .name }}")
+
+	noColor := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() {
+		color.NoColor = noColor
+	})
+
+	var out bytes.Buffer
+	p := &watchPrinter{out: &out, redraw: true, header: "Every 5s: metalctl network list"}
+
+	require.NoError(t, p.Print([]*models.V1NetworkResponse{{ID: new("1"), Name: "a"}, {ID: new("2"), Name: "b"}}))
+	assert.Contains(t, out.String(), clearScreen+"Every 5s: metalctl network list")
+	assert.NotContains(t, out.String(), "\x1b[7m", "first listing must not highlight rows")
+
+	out.Reset()
+	require.NoError(t, p.Print([]*models.V1NetworkResponse{{ID: new("1"), Name: "a"}, {ID: new("2"), Name: "changed"}}))
+	assert.Contains(t, out.String(), "\n1 a\n")
+	assert.Contains(t, out.String(), "\x1b[7m2 changed\x1b[27m")
+}
+
+func Test_watchPrinter_appendsWithoutTerminal(t *testing.T) {
+	defer viper.Reset()
+	viper.Set("output-format", "template")
+	viper.Set("template", "{{ .id }} {{ .name }}")
+
+	var out bytes.Buffer
+	p := &watchPrinter{out: &out, header: "Every 5s: metalctl network list"}
+
+	require.NoError(t, p.Print([]*models.V1NetworkResponse{{ID: new("1"), Name: "a"}}))
+	require.NoError(t, p.Print([]*models.V1NetworkResponse{{ID: new("1"), Name: "b"}}))
+
+	assert.NotContains(t, out.String(), clearScreen)
+	assert.Equal(t, 2, strings.Count(out.String(), "Every 5s: metalctl network list"))
+	assert.Contains(t, out.String(), "\n1 a\n")
+	assert.Contains(t, out.String(), "\n1 b\n")
+}
+
+func Test_watch_errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantPolls int
+		wantErr   bool
+	}{
+		{
+			name:      "keeps polling on server errors",
+			err:       machine.NewFindMachinesDefault(http.StatusServiceUnavailable),
+			wantPolls: 3,
+		},
+		{
+			name:      "keeps polling on network errors",
+			err:       fmt.Errorf("dial tcp: %w", syscall.ECONNREFUSED),
+			wantPolls: 3,
+		},
+		{
+			name:      "aborts on unauthorized",
+			err:       machine.NewFindMachinesDefault(http.StatusUnauthorized),
+			wantPolls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "aborts on forbidden",
+			err:       machine.NewFindMachinesDefault(http.StatusForbidden),
+			wantPolls: 1,
+			wantErr:   true,
+		},
+		{
+			name:      "aborts on usage errors",
+			err:       &usageError{err: errors.New("invalid filter")},
+			wantPolls: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer viper.Reset()
+			viper.Set("interval", time.Millisecond)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			c := &config{ctx: ctx, out: &bytes.Buffer{}, log: slog.New(slog.DiscardHandler)}
+
+			polls := 0
+			err := c.watch("metalctl machine list", func() error {
+				polls++
+				if polls == tt.wantPolls {
+					cancel()
+				}
+				return tt.err
+			})
+
+			if tt.wantErr {
+				require.ErrorIs(t, err, tt.err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantPolls, polls)
+		})
+	}
+}
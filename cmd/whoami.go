@@ -2,9 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/metal-stack/metal-lib/jwt/sec"
+	"github.com/metal-stack/metalctl/pkg/api"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -15,37 +17,88 @@ func newWhoamiCmd(c *config) *cobra.Command {
 		Short: "shows current user",
 		Long:  "shows the current user, that will be used to authenticate commands.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-
-			authContext, err := getAuthContext(viper.GetString("kubeconfig"))
-			if err != nil {
-				return err
+			var token string
+			if c.token != nil {
+				token = c.token.get()
 			}
 
-			if !authContext.AuthProviderOidc {
-				return fmt.Errorf("active user %s has no oidc authProvider, check config", authContext.User)
+			if token == "" {
+				authContext, err := getAuthContext(viper.GetString("kubeconfig"))
+				if err != nil {
+					return err
+				}
+
+				if !authContext.AuthProviderOidc {
+					return fmt.Errorf("active user %s has no oidc authProvider, check config", authContext.User)
+				}
+
+				token = authContext.IDToken
 			}
 
-			user, parsedClaims, err := sec.ParseTokenUnvalidatedUnfiltered(authContext.IDToken)
+			user, parsedClaims, err := sec.ParseTokenUnvalidatedUnfiltered(token)
 			if err != nil {
 				return err
 			}
 
-			_, _ = fmt.Fprintf(c.out, "UserId: %s\n", user.Name)
-			if user.Tenant != "" {
-				_, _ = fmt.Fprintf(c.out, "Tenant: %s\n", user.Tenant)
-			}
-			if user.Issuer != "" {
-				_, _ = fmt.Fprintf(c.out, "Issuer: %s\n", user.Issuer)
+			expiresAt := time.Unix(parsedClaims.ExpiresAt, 0)
+
+			whoami := &api.WhoAmI{
+				User:              user.Name,
+				Tenant:            user.Tenant,
+				Issuer:            user.Issuer,
+				ExpiresAt:         expiresAt,
+				RemainingValidity: remainingValidity(expiresAt).String(),
+				APIURL:            c.driverURL,
 			}
-			_, _ = fmt.Fprintf(c.out, "Groups:\n")
+
 			for _, g := range user.Groups {
-				_, _ = fmt.Fprintf(c.out, " %s\n", g)
+				whoami.Groups = append(whoami.Groups, string(g))
 			}
 
-			_, _ = fmt.Fprintf(c.out, "Expires at %s\n", time.Unix(parsedClaims.ExpiresAt, 0).Format("Mon Jan 2 15:04:05 MST 2006"))
+			ctx := api.MustDefaultContext()
+			if whoami.APIURL == "" {
+				whoami.APIURL = viper.GetString("api-url")
+			}
+			if whoami.APIURL == "" {
+				whoami.APIURL = ctx.ApiURL
+			}
+			if cs, err := api.GetContexts(); err == nil {
+				whoami.Context = cs.ActiveContextName()
+			}
 
-			return nil
+			return c.describePrinter.Print(whoami)
 		},
 	}
 	return whoamiCmd
 }
+
+// warnTokenExpiry logs a warning in case the given token expires within the given window
+func warnTokenExpiry(log *slog.Logger, token string, window time.Duration) {
+	if token == "" || window <= 0 {
+		return
+	}
+
+	_, claims, err := sec.ParseTokenUnvalidatedUnfiltered(token)
+	if err != nil || claims.ExpiresAt == 0 {
+		return
+	}
+
+	expiresAt := time.Unix(claims.ExpiresAt, 0)
+	remaining := remainingValidity(expiresAt)
+
+	switch {
+	case remaining == 0:
+		log.Warn("your token is expired, please run metalctl login", "expired-at", expiresAt.Format(time.RFC3339))
+	case remaining < window:
+		log.Warn("your token expires soon, please run metalctl login", "remaining", remaining.String())
+	}
+}
+
+// remainingValidity returns the duration until the given expiry in seconds precision, zero if already expired
+func remainingValidity(expiresAt time.Time) time.Duration {
+	remaining := time.Until(expiresAt).Truncate(time.Second)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
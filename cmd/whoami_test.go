@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/metal-stack/metal-lib/auth"
+	"github.com/metal-stack/metalctl/pkg/api"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WhoamiCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	cfgFile := filepath.Join(dir, "config.yaml")
+	cfg := []byte(`current: test
+contexts:
+  test:
+    url: https://metal.test
+`)
+	require.NoError(t, os.WriteFile(cfgFile, cfg, 0600))
+
+	expiresAt := time.Unix(testTime.Add(time.Hour).Unix(), 0)
+
+	kubeconfig := filepath.Join(dir, "kubeconfig")
+	_, err := auth.UpdateKubeConfigContext(kubeconfig, auth.TokenInfo{
+		IDToken: mustSignToken(t, map[string]any{
+			"iss":              "https://dex.test",
+			"sub":              "1234",
+			"exp":              expiresAt.Unix(),
+			"name":             "jane",
+			"groups":           []string{"tnnt-all-admin"},
+			"federated_claims": map[string]string{"connector_id": "tnnt_ldap"},
+		}),
+		TokenClaims: auth.Claims{Issuer: "https://dex.test"},
+	}, auth.ExtractName, formatContextName(cloudContext, "test"))
+	require.NoError(t, err)
+
+	tests := []*test[*api.WhoAmI]{
+		{
+			name: "whoami",
+			cmd: func(want *api.WhoAmI) []string {
+				return []string{"whoami", "--config", cfgFile, "--kubeconfig", kubeconfig}
+			},
+			fsMocks: func(fs afero.Fs, want *api.WhoAmI) {
+				require.NoError(t, afero.WriteFile(fs, cfgFile, cfg, 0600))
+			},
+			want: &api.WhoAmI{
+				User:              "jane",
+				Tenant:            "tnnt",
+				Issuer:            "https://dex.test",
+				Groups:            []string{"tnnt-all-admin"},
+				ExpiresAt:         expiresAt,
+				RemainingValidity: "59m59s",
+				Context:           "test",
+				APIURL:            "https://metal.test",
+			},
+			wantTable: new(`
+USER  TENANT  CONTEXT  EXPIRES IN
+jane  tnnt    test     59m59s
+`),
+		},
+	}
+	for _, tt := range tests {
+		tt.testCmd(t)
+	}
+}
+
+func Test_remainingValidity(t *testing.T) {
+	assert.Equal(t, time.Hour, remainingValidity(testTime.Add(time.Hour)))
+	assert.Equal(t, 59*time.Minute+59*time.Second, remainingValidity(time.Unix(testTime.Add(time.Hour).Unix(), 0)))
+	assert.Equal(t, time.Duration(0), remainingValidity(testTime.Add(-time.Hour)))
+}
+
+func mustSignToken(t *testing.T, claims map[string]any) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, nil)
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	token, err := jws.CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
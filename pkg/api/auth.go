@@ -14,7 +14,7 @@ func GetAuthContext(kubeconfig string) (*auth.AuthContext, error) {
 	if err != nil {
 		return nil, err
 	}
-	authContext, err := auth.GetAuthContext(kubeconfig, FormatContextName(CloudContext, cs.CurrentContext))
+	authContext, err := auth.GetAuthContext(kubeconfig, FormatContextName(CloudContext, cs.ActiveContextName()))
 	if err != nil {
 		return nil, err
 	}
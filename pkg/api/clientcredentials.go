@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ClientCredentialsToken is a token obtained through the oauth2 client credentials grant,
+// it is cached per context outside of the kubeconfig
+type ClientCredentialsToken struct {
+	Token     string    `json:"token"`
+	Expiry    time.Time `json:"expiry"`
+	ClientID  string    `json:"client_id"`
+	IssuerURL string    `json:"issuer_url"`
+	ApiURL    string    `json:"api_url"`
+}
+
+// ClientCredentialsConfig contains the parameters for the oauth2 client credentials grant
+type ClientCredentialsConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// ApiURL is the metal-api the token is used for, the cached token is only used for the same api
+	ApiURL string
+
+	// HTTPClient is used for the communication with the issuer, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// AcquireClientCredentialsToken runs the oauth2 client credentials grant against the issuer and stores
+// the token in the token cache of the given context
+func AcquireClientCredentialsToken(ctx context.Context, contextName string, config ClientCredentialsConfig) (*ClientCredentialsToken, error) {
+	if config.IssuerURL == "" {
+		return nil, fmt.Errorf("no issuer url configured in context")
+	}
+	if config.ClientID == "" || config.ClientSecret == "" {
+		return nil, fmt.Errorf("client id and client secret must be configured in context for the client credentials grant")
+	}
+	if config.HTTPClient != nil {
+		ctx = oidc.ClientContext(ctx, config.HTTPClient)
+	}
+
+	provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to discover issuer %s: %w", config.IssuerURL, err)
+	}
+
+	ccConfig := clientcredentials.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		TokenURL:     provider.Endpoint().TokenURL,
+		Scopes:       config.Scopes,
+	}
+
+	token, err := ccConfig.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve token: %w", err)
+	}
+
+	// issuers may return an id token, which is preferred as it carries the user claims
+	rawToken := token.AccessToken
+	if idToken, ok := token.Extra("id_token").(string); ok && idToken != "" {
+		rawToken = idToken
+	}
+
+	result := &ClientCredentialsToken{
+		Token:     rawToken,
+		Expiry:    token.Expiry,
+		ClientID:  config.ClientID,
+		IssuerURL: config.IssuerURL,
+		ApiURL:    config.ApiURL,
+	}
+
+	err = writeClientCredentialsToken(contextName, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CachedClientCredentialsToken returns the cached token of the given context, if it was issued by the issuer and for the client
+// and api of the given config. An error wrapping fs.ErrNotExist is returned in case the context was not logged in with client credentials.
+func CachedClientCredentialsToken(contextName string, config ClientCredentialsConfig) (*ClientCredentialsToken, error) {
+	path, err := tokenCacheFile(contextName)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var token ClientCredentialsToken
+	err = json.Unmarshal(raw, &token)
+	if err != nil {
+		return nil, fmt.Errorf("token cache %s is unreadable: %w", path, err)
+	}
+
+	// the token must not be sent to an api or accepted from an issuer, which the context was changed to after the login
+	if token.IssuerURL != config.IssuerURL || token.ClientID != config.ClientID || token.ApiURL != config.ApiURL {
+		return nil, fmt.Errorf("token cache %s was acquired for issuer %q, client %q and api %q, which does not match the context, please run metalctl login", path, token.IssuerURL, token.ClientID, token.ApiURL)
+	}
+
+	return &token, nil
+}
+
+// RemoveClientCredentialsToken removes the cached token of the given context, returns false if there was none
+func RemoveClientCredentialsToken(contextName string) (bool, error) {
+	path, err := tokenCacheFile(contextName)
+	if err != nil {
+		return false, err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RenameClientCredentialsToken moves the cached token of a renamed context, nothing is done if there is none
+func RenameClientCredentialsToken(oldContextName, newContextName string) error {
+	oldPath, err := tokenCacheFile(oldContextName)
+	if err != nil {
+		return err
+	}
+	newPath, err := tokenCacheFile(newContextName)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(oldPath, newPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// Expired returns true if the token is expired or expires within a short leeway, tokens without expiry never expire
+func (t *ClientCredentialsToken) Expired() bool {
+	if t.Expiry.IsZero() {
+		return false
+	}
+	return t.Expiry.Add(-tokenExpiryLeeway).Before(time.Now())
+}
+
+func writeClientCredentialsToken(contextName string, token *ClientCredentialsToken) error {
+	path, err := tokenCacheFile(contextName)
+	if err != nil {
+		return err
+	}
+
+	raw, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, raw, 0600)
+}
+
+func tokenCacheFile(contextName string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to figure out user cache directory: %w", err)
+	}
+
+	return filepath.Join(dir, "metalctl", "tokens", contextFileName(contextName)+".json"), nil
+}
+
+// contextFileName escapes the context name for the usage as file name, such that it cannot point outside of the directory
+func contextFileName(contextName string) string {
+	if contextName == "" {
+		return "default"
+	}
+	return strings.ReplaceAll(url.PathEscape(contextName), ".", "%2E")
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCredentialsToken(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+
+	issuer := newFakeIssuer(t, "ci", 0)
+
+	config := ClientCredentialsConfig{
+		IssuerURL:    issuer.URL,
+		ClientID:     "ci",
+		ClientSecret: "secret",
+		Scopes:       []string{"metal"},
+		ApiURL:       "https://api.metal-stack.io/metal",
+		HTTPClient:   issuer.Client(),
+	}
+
+	_, err := CachedClientCredentialsToken("ci-context", config)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+
+	token, err := AcquireClientCredentialsToken(context.Background(), "ci-context", config)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.Token)
+	assert.Equal(t, "ci", token.ClientID)
+	assert.Equal(t, issuer.URL, token.IssuerURL)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.Expiry, time.Minute)
+	assert.False(t, token.Expired())
+
+	info, err := os.Stat(filepath.Join(cacheDir, "metalctl", "tokens", "ci-context.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	cached, err := CachedClientCredentialsToken("ci-context", config)
+	require.NoError(t, err)
+	assert.Equal(t, token.Token, cached.Token)
+	assert.True(t, token.Expiry.Equal(cached.Expiry))
+
+	for name, mismatch := range map[string]ClientCredentialsConfig{
+		"issuer": {IssuerURL: "https://evil", ClientID: config.ClientID, ApiURL: config.ApiURL},
+		"client": {IssuerURL: config.IssuerURL, ClientID: "other", ApiURL: config.ApiURL},
+		"api":    {IssuerURL: config.IssuerURL, ClientID: config.ClientID, ApiURL: "https://evil/metal"},
+	} {
+		_, err = CachedClientCredentialsToken("ci-context", mismatch)
+		require.ErrorContains(t, err, "which does not match the context, please run metalctl login", name)
+	}
+
+	require.NoError(t, RenameClientCredentialsToken("ci-context", "renamed"))
+	_, err = CachedClientCredentialsToken("ci-context", config)
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	cached, err = CachedClientCredentialsToken("renamed", config)
+	require.NoError(t, err)
+	assert.Equal(t, token.Token, cached.Token)
+	require.NoError(t, RenameClientCredentialsToken("renamed", "ci-context"))
+	require.NoError(t, RenameClientCredentialsToken("not-logged-in", "other"))
+
+	removed, err := RemoveClientCredentialsToken("ci-context")
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = RemoveClientCredentialsToken("ci-context")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	config.ClientSecret = "wrong"
+	_, err = AcquireClientCredentialsToken(context.Background(), "ci-context", config)
+	require.ErrorContains(t, err, "invalid_client")
+
+	assert.Equal(t, int32(1), issuer.polls.Load())
+}
+
+func TestTokenCacheFile(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+
+	tokens := filepath.Join(cacheDir, "metalctl", "tokens")
+
+	for name, want := range map[string]string{
+		"":              "default.json",
+		"ci-context":    "ci-context.json",
+		"../../../evil": "%2E%2E%2F%2E%2E%2F%2E%2E%2Fevil.json",
+		"a/b":           "a%2Fb.json",
+		"..":            "%2E%2E.json",
+	} {
+		path, err := tokenCacheFile(name)
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(tokens, want), path, name)
+	}
+}
+
+func TestClientCredentialsTokenExpired(t *testing.T) {
+	assert.False(t, (&ClientCredentialsToken{}).Expired())
+	assert.False(t, (&ClientCredentialsToken{Expiry: time.Now().Add(time.Hour)}).Expired())
+	assert.True(t, (&ClientCredentialsToken{Expiry: time.Now().Add(10 * time.Second)}).Expired())
+	assert.True(t, (&ClientCredentialsToken{Expiry: time.Now().Add(-time.Hour)}).Expired())
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFileName        = "config.yaml"
+	projectConfigFileName = ".metalctl.yaml"
+)
+
+// systemConfigDir contains the read-only system wide config
+var systemConfigDir = "/etc/metalctl"
+
+// ConfigLayers returns the config files in the order in which they are merged, later files take precedence.
+// These are a read-only system file, the user file and a project-local file in the current directory.
+// If a config file is given explicitly, it is the only layer.
+func ConfigLayers() []string {
+	layers := TrustedConfigLayers()
+	if project := ProjectConfigFile(); project != "" {
+		layers = append(layers, project)
+	}
+	return layers
+}
+
+// TrustedConfigLayers returns the config files, which are allowed to set every key, i.e. all layers except the project config
+func TrustedConfigLayers() []string {
+	if cfgFile := viper.GetString("config"); cfgFile != "" {
+		return []string{cfgFile}
+	}
+
+	layers := []string{filepath.Join(systemConfigDir, configFileName)}
+	if userCfg, err := UserConfigFile(); err == nil {
+		layers = append(layers, userCfg)
+	}
+
+	return layers
+}
+
+// ProjectConfigFile returns the project-local config file in the current directory, an empty string if a config file
+// is given explicitly. It comes with every cloned repository, so it may only set the defaults and columns of contexts
+// defined in the trusted layers, such that it can neither run a credential helper nor redirect the credentials.
+func ProjectConfigFile() string {
+	if viper.GetString("config") != "" {
+		return ""
+	}
+	return projectConfigFileName
+}
+
+// UserConfigFile returns the config file to which metalctl writes changes
+func UserConfigFile() (string, error) {
+	if cfgFile := viper.GetString("config"); cfgFile != "" {
+		return cfgFile, nil
+	}
+
+	h, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to figure out user home directory: %w", err)
+	}
+
+	return filepath.Join(h, ".metalctl", configFileName), nil
+}
+
+// UpdateContexts applies the given function to the contexts of the user config file.
+// The file is locked during the update and written atomically, other keys in the file are preserved.
+func UpdateContexts(fn func(ctxs *Contexts) error) error {
+	path, err := UserConfigFile()
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return err
+	}
+
+	return withFileLock(path, func() error {
+		var (
+			raw  = map[string]any{}
+			ctxs = &Contexts{}
+		)
+
+		content, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		err = yaml.Unmarshal(content, &raw)
+		if err != nil {
+			return fmt.Errorf("config %s file unreadable: %w", path, err)
+		}
+		err = yaml.Unmarshal(content, ctxs)
+		if err != nil {
+			return fmt.Errorf("config %s file unreadable: %w", path, err)
+		}
+		if ctxs.Contexts == nil {
+			ctxs.Contexts = map[string]Context{}
+		}
+
+		err = fn(ctxs)
+		if err != nil {
+			return err
+		}
+
+		raw["current"] = ctxs.CurrentContext
+		raw["previous"] = ctxs.PreviousContext
+		raw["contexts"] = ctxs.Contexts
+
+		content, err = yaml.Marshal(raw)
+		if err != nil {
+			return err
+		}
+
+		return writeFileAtomic(path, content, 0600)
+	})
+}
+
+func readContextsFile(path string) (*Contexts, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ctxs Contexts
+	err = yaml.Unmarshal(content, &ctxs)
+	if err != nil {
+		return nil, fmt.Errorf("config %s file unreadable: %w", path, err)
+	}
+
+	return &ctxs, nil
+}
+
+// readProjectContextsFile reads the project config, which is only allowed to set the defaults and columns of contexts
+func readProjectContextsFile(path string) (*Contexts, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	err = yaml.Unmarshal(content, &raw)
+	if err != nil {
+		return nil, fmt.Errorf("config %s file unreadable: %w", path, err)
+	}
+
+	for key, value := range raw {
+		if key != "contexts" {
+			return nil, fmt.Errorf("project config %s may only set the defaults and columns of contexts, %q is not allowed", path, key)
+		}
+
+		ctxs, _ := value.(map[string]any)
+		for name, ctx := range ctxs {
+			fields, _ := ctx.(map[string]any)
+			for field := range fields {
+				if field != "defaults" && field != "columns" {
+					return nil, fmt.Errorf("project config %s may only set the defaults and columns of contexts, %q of context %q is not allowed", path, field, name)
+				}
+			}
+		}
+	}
+
+	return readContextsFile(path)
+}
+
+// mergeProject applies the defaults and columns of the project config to the existing contexts
+func (cs *Contexts) mergeProject(path string, project *Contexts) error {
+	for name, pc := range project.Contexts {
+		ctx, ok := cs.Contexts[name]
+		if !ok {
+			return fmt.Errorf("project config %s sets context %q, which does not exist", path, name)
+		}
+
+		ctx.ProjectDefaults = pc.Defaults
+		if len(pc.Columns) > 0 {
+			ctx.Columns = maps.Clone(ctx.Columns)
+			if ctx.Columns == nil {
+				ctx.Columns = map[string][]string{}
+			}
+			maps.Copy(ctx.Columns, pc.Columns)
+		}
+
+		cs.Contexts[name] = ctx
+	}
+
+	return nil
+}
+
+// merge applies the given contexts on top, contexts with the same name are replaced
+func (cs *Contexts) merge(other *Contexts) {
+	if other.CurrentContext != "" {
+		cs.CurrentContext = other.CurrentContext
+	}
+	if other.PreviousContext != "" {
+		cs.PreviousContext = other.PreviousContext
+	}
+	if cs.Contexts == nil {
+		cs.Contexts = map[string]Context{}
+	}
+	maps.Copy(cs.Contexts, other.Contexts)
+}
+
+// writeFileAtomic writes to a temporary file first and renames it afterwards, such that readers never see a partially written file
+func writeFileAtomic(path string, content []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	_, err = tmp.Write(content)
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	err = tmp.Sync()
+	if err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tmp.Name(), perm)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// withFileLock holds an advisory lock on a lock file next to the given path while running the given function
+func withFileLock(path string, fn func() error) error {
+	f, err := os.OpenFile(path+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	err = lockFile(f)
+	if err != nil {
+		return fmt.Errorf("unable to lock config file %s: %w", path, err)
+	}
+	defer func() {
+		_ = unlockFile(f)
+	}()
+
+	return fn()
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func setupConfigLayers(t *testing.T) (system, user, project string) {
+	dir := t.TempDir()
+
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	oldSystemConfigDir := systemConfigDir
+	systemConfigDir = filepath.Join(dir, "etc")
+	t.Cleanup(func() { systemConfigDir = oldSystemConfigDir })
+
+	t.Setenv("HOME", filepath.Join(dir, "home"))
+	t.Chdir(t.TempDir())
+
+	system = filepath.Join(systemConfigDir, "config.yaml")
+	user = filepath.Join(dir, "home", ".metalctl", "config.yaml")
+	project = ".metalctl.yaml"
+
+	return system, user, project
+}
+
+func writeConfig(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestGetContexts_Layered(t *testing.T) {
+	system, user, project := setupConfigLayers(t)
+
+	writeConfig(t, system, `---
+current: prod
+contexts:
+  prod:
+    url: https://api.system/metal
+  dev:
+    url: https://api.dev/metal
+`)
+	writeConfig(t, user, `---
+previous: dev
+contexts:
+  prod:
+    url: https://api.user/metal
+`)
+	writeConfig(t, project, `---
+contexts:
+  prod:
+    defaults:
+      partition: fra-equ01
+    columns:
+      machine: [id, hostname]
+`)
+
+	got, err := GetContexts()
+	require.NoError(t, err)
+
+	want := &Contexts{
+		CurrentContext:  "prod",
+		PreviousContext: "dev",
+		Contexts: map[string]Context{
+			"prod": {
+				ApiURL:          "https://api.user/metal",
+				Columns:         map[string][]string{"machine": {"id", "hostname"}},
+				ProjectDefaults: map[string]string{"partition": "fra-equ01"},
+			},
+			"dev": {ApiURL: "https://api.dev/metal"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("diff (+got -want):\n %s", diff)
+	}
+}
+
+func TestUpdateContexts(t *testing.T) {
+	system, user, _ := setupConfigLayers(t)
+
+	writeConfig(t, system, `---
+contexts:
+  system:
+    url: https://api.system/metal
+`)
+	writeConfig(t, user, `---
+api-token: i-am-token
+current: a
+contexts:
+  a:
+    url: https://api.a/metal
+`)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			err := UpdateContexts(func(ctxs *Contexts) error {
+				ctxs.Contexts[fmt.Sprintf("ctx-%d", i)] = Context{ApiURL: "https://api.metal-stack.io"}
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	content, err := os.ReadFile(user)
+	require.NoError(t, err)
+
+	var raw map[string]any
+	require.NoError(t, yaml.Unmarshal(content, &raw))
+	require.Equal(t, "i-am-token", raw["api-token"], "unrelated keys must be preserved")
+
+	ctxs, err := readContextsFile(user)
+	require.NoError(t, err)
+	require.Len(t, ctxs.Contexts, 21, "no update must get lost and other layers must not be written")
+	require.Equal(t, "a", ctxs.CurrentContext)
+
+	entries, err := os.ReadDir(filepath.Dir(user))
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	require.ElementsMatch(t, []string{"config.yaml", "config.yaml.lock"}, names, "no temporary files must be left over")
+}
+
+func TestGetContexts_ProjectRestricted(t *testing.T) {
+	tests := []struct {
+		name    string
+		project string
+		wantErr string
+	}{
+		{
+			name: "current context",
+			project: `---
+current: prod
+`,
+			wantErr: `project config .metalctl.yaml may only set the defaults and columns of contexts, "current" is not allowed`,
+		},
+		{
+			name: "api url",
+			project: `---
+contexts:
+  prod:
+    url: https://evil/metal
+`,
+			wantErr: `project config .metalctl.yaml may only set the defaults and columns of contexts, "url" of context "prod" is not allowed`,
+		},
+		{
+			name: "credential helper",
+			project: `---
+contexts:
+  prod:
+    credential_helper: [sh, -c, "curl evil"]
+`,
+			wantErr: `project config .metalctl.yaml may only set the defaults and columns of contexts, "credential_helper" of context "prod" is not allowed`,
+		},
+		{
+			name: "unknown context",
+			project: `---
+contexts:
+  local:
+    defaults:
+      partition: fra-equ01
+`,
+			wantErr: `project config .metalctl.yaml sets context "local", which does not exist`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, user, project := setupConfigLayers(t)
+
+			writeConfig(t, user, `---
+current: prod
+contexts:
+  prod:
+    url: https://api.user/metal
+`)
+			writeConfig(t, project, tt.project)
+
+			_, err := GetContexts()
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	t.Run("explicit config file", func(t *testing.T) {
+		_, user, project := setupConfigLayers(t)
+
+		writeConfig(t, user, `---
+current: prod
+contexts:
+  prod:
+    url: https://api.user/metal
+`)
+		writeConfig(t, project, `---
+current: local
+`)
+		viper.Set("config", user)
+
+		got, err := GetContexts()
+		require.NoError(t, err, "the project config is not read when a config file is given explicitly")
+		require.Equal(t, "prod", got.CurrentContext)
+	})
+}
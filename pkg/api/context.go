@@ -1,12 +1,16 @@
 package api
 
 import (
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/fatih/color"
 	"github.com/spf13/viper"
-	"gopkg.in/yaml.v3"
 )
 
 // Contexts contains all configuration contexts of metalctl
@@ -18,46 +22,122 @@ type Contexts struct {
 
 // Context configure metalctl behaviour
 type Context struct {
-	ApiURL                   string  `yaml:"url"`
-	CertificateAuthorityData string  `yaml:"certificate_authority_data,omitempty"`
-	IssuerURL                string  `yaml:"issuer_url"`
-	IssuerType               string  `yaml:"issuer_type"`
-	CustomScopes             string  `yaml:"custom_scopes"`
-	ClientID                 string  `yaml:"client_id"`
-	ClientSecret             string  `yaml:"client_secret"`
-	HMAC                     *string `yaml:"hmac"`
-	HMACAuthType             string  `yaml:"hmac_auth_type,omitempty"`
+	ApiURL                   string  `json:"url" yaml:"url"`
+	CertificateAuthorityData string  `json:"certificate_authority_data,omitempty" yaml:"certificate_authority_data,omitempty"`
+	IssuerURL                string  `json:"issuer_url" yaml:"issuer_url"`
+	IssuerType               string  `json:"issuer_type" yaml:"issuer_type"`
+	CustomScopes             string  `json:"custom_scopes" yaml:"custom_scopes"`
+	ClientID                 string  `json:"client_id" yaml:"client_id"`
+	ClientSecret             string  `json:"client_secret" yaml:"client_secret"`
+	HMAC                     *string `json:"hmac" yaml:"hmac"`
+	HMACAuthType             string  `json:"hmac_auth_type,omitempty" yaml:"hmac_auth_type,omitempty"`
+	CertificateAuthorityFile string  `json:"certificate_authority_file,omitempty" yaml:"certificate_authority_file,omitempty"`
+	// ClientCertificate and ClientKey are paths to pem files, the data variants contain the base64 encoded pem instead
+	ClientCertificate     string `json:"client_certificate,omitempty" yaml:"client_certificate,omitempty"`
+	ClientCertificateData string `json:"client_certificate_data,omitempty" yaml:"client_certificate_data,omitempty"`
+	ClientKey             string `json:"client_key,omitempty" yaml:"client_key,omitempty"`
+	ClientKeyData         string `json:"client_key_data,omitempty" yaml:"client_key_data,omitempty"`
+	// InsecureSkipVerify disables the verification of the metal-api server certificate, never use this in production
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty"`
+	TLSServerName      string `json:"tls_server_name,omitempty" yaml:"tls_server_name,omitempty"`
+	// ProxyURL is used for connecting to the metal-api, the proxy environment variables are used if not set
+	ProxyURL string `json:"proxy_url,omitempty" yaml:"proxy_url,omitempty"`
+	// CredentialHelper can be used for fetching the hmac, client secret or token from an external command
+	CredentialHelper *CredentialHelper `json:"credential_helper,omitempty" yaml:"credential_helper,omitempty"`
+	// Defaults contains default values for command line flags, which are applied when using this context
+	Defaults map[string]string `json:"defaults,omitempty" yaml:"defaults,omitempty"`
+	// Retry configures the retries of idempotent requests, the default retry policy is used if not set
+	Retry *RetryPolicy `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// CompletionCacheTTL is the duration shell completion results are cached, zero disables the cache
+	CompletionCacheTTL *time.Duration `json:"completion_cache_ttl,omitempty" yaml:"completion_cache_ttl,omitempty"`
+	// Columns selects and orders the columns of tables by table name, e.g. machine: [id, hostname, rack]
+	Columns map[string][]string `json:"columns,omitempty" yaml:"columns,omitempty"`
+
+	// ProjectDefaults are the defaults of the project config, which must only be applied to the flags of a command itself
+	// and never to global flags like the api url or credentials. They are never written to a config file.
+	ProjectDefaults map[string]string `json:"-" yaml:"-"`
 }
 
-var defaultCtx = Context{
-	ApiURL:       "http://localhost:8080/cloud",
-	IssuerURL:    "http://localhost:8080/",
-	HMACAuthType: "Metal-Admin",
+// NamedContext is a single context together with its name, used for describing a context
+type NamedContext struct {
+	Name    string `json:"name" yaml:"name"`
+	Current bool   `json:"current" yaml:"current"`
+	Context `yaml:",inline"`
 }
 
+const redacted = "<redacted>"
+
+var (
+	defaultCtx = Context{
+		ApiURL:       "http://localhost:8080/cloud",
+		IssuerURL:    "http://localhost:8080/",
+		HMACAuthType: "Metal-Admin",
+	}
+
+	// IssuerTypes contains the supported values for the issuer type of a context, empty defaults to dex
+	IssuerTypes = []string{"dex", "generic"}
+	// HMACAuthTypes contains the supported values for the hmac auth type of a context
+	HMACAuthTypes = []string{"Metal-Admin", "Metal-Edit", "Metal-View"}
+	// ContextFields contains the names of the fields of a context that can be set through SetField
+	ContextFields = []string{"url", "certificate_authority_data", "certificate_authority_file", "client_certificate", "client_certificate_data", "client_key", "client_key_data", "insecure_skip_verify", "tls_server_name", "proxy_url", "issuer_url", "issuer_type", "custom_scopes", "client_id", "client_secret", "hmac", "hmac_auth_type", "credential_helper", DefaultsFieldPrefix + "<flag>", RetryFieldPrefix + "max_retries", RetryFieldPrefix + "initial_backoff", RetryFieldPrefix + "max_backoff", "completion_cache_ttl", ColumnsFieldPrefix + "<table>"}
+)
+
+// DefaultCompletionCacheTTL is used for contexts without a completion cache ttl
+const DefaultCompletionCacheTTL = 5 * time.Minute
+
+// DefaultsFieldPrefix is the prefix for setting a flag default of a context through SetField
+const DefaultsFieldPrefix = "defaults."
+
+// ColumnsFieldPrefix is the prefix for setting the columns of a table through SetField, the value is a comma separated list
+const ColumnsFieldPrefix = "columns."
+
+// GetContexts returns the contexts merged from all config layers
 func GetContexts() (*Contexts, error) {
-	var ctxs Contexts
-	cfgFile := viper.ConfigFileUsed()
-	c, err := os.ReadFile(cfgFile)
-	if err != nil {
-		return nil, fmt.Errorf("unable to read config, please create a config.yaml in either: /etc/metalctl/, $HOME/.metalctl/ or in the current directory, see metalctl ctx -h for examples")
+	var (
+		merged = &Contexts{Contexts: map[string]Context{}}
+		found  bool
+	)
+
+	for _, path := range TrustedConfigLayers() {
+		ctxs, err := readContextsFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		found = true
+		merged.merge(ctxs)
 	}
-	err = yaml.Unmarshal(c, &ctxs)
-	return &ctxs, err
-}
 
-func WriteContexts(ctxs *Contexts) error {
-	c, err := yaml.Marshal(ctxs)
-	if err != nil {
-		return err
+	if !found {
+		return nil, fmt.Errorf("unable to read config, please create a config.yaml in either: /etc/metalctl/ or $HOME/.metalctl/, see metalctl ctx -h for examples")
 	}
-	cfgFile := viper.ConfigFileUsed()
-	err = os.WriteFile(cfgFile, c, 0600)
-	if err != nil {
-		return err
+
+	if path := ProjectConfigFile(); path != "" {
+		project, err := readProjectContextsFile(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if err == nil {
+			err = merged.mergeProject(path, project)
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
-	fmt.Printf("%s switched context to \"%s\"\n", color.GreenString("✔"), color.GreenString(ctxs.CurrentContext))
-	return nil
+
+	return merged, nil
+}
+
+// ActiveContextName returns the name of the context that is used by metalctl. The current context of the config file
+// can be overridden with the --context flag or the METALCTL_CONTEXT environment variable without persisting it.
+func (cs *Contexts) ActiveContextName() string {
+	if name := viper.GetString("context"); name != "" {
+		return name
+	}
+	return cs.CurrentContext
 }
 
 func MustDefaultContext() Context {
@@ -65,7 +145,7 @@ func MustDefaultContext() Context {
 	if err != nil {
 		return defaultCtx
 	}
-	ctx, ok := ctxs.Contexts[ctxs.CurrentContext]
+	ctx, ok := ctxs.Contexts[ctxs.ActiveContextName()]
 	if !ok {
 		return defaultCtx
 	}
@@ -74,3 +154,215 @@ func MustDefaultContext() Context {
 	}
 	return ctx
 }
+
+// Validate checks the context for invalid values
+func (c *Context) Validate() error {
+	var errs []error
+
+	if c.ApiURL == "" {
+		errs = append(errs, fmt.Errorf("url must be set"))
+	} else if err := validateURL(c.ApiURL); err != nil {
+		errs = append(errs, fmt.Errorf("url is invalid: %w", err))
+	}
+	if c.IssuerURL != "" {
+		if err := validateURL(c.IssuerURL); err != nil {
+			errs = append(errs, fmt.Errorf("issuer_url is invalid: %w", err))
+		}
+	}
+	if c.IssuerType != "" && !slices.Contains(IssuerTypes, c.IssuerType) {
+		errs = append(errs, fmt.Errorf("issuer_type %q is invalid, must be one of: %s", c.IssuerType, strings.Join(IssuerTypes, "|")))
+	}
+	if c.HMACAuthType != "" && !slices.Contains(HMACAuthTypes, c.HMACAuthType) {
+		errs = append(errs, fmt.Errorf("hmac_auth_type %q is invalid, must be one of: %s", c.HMACAuthType, strings.Join(HMACAuthTypes, "|")))
+	}
+	if c.CertificateAuthorityData != "" && c.CertificateAuthorityFile != "" {
+		errs = append(errs, fmt.Errorf("only one of certificate_authority_data and certificate_authority_file can be set"))
+	}
+	if c.ClientCertificate != "" && c.ClientCertificateData != "" {
+		errs = append(errs, fmt.Errorf("only one of client_certificate and client_certificate_data can be set"))
+	}
+	if c.ClientKey != "" && c.ClientKeyData != "" {
+		errs = append(errs, fmt.Errorf("only one of client_key and client_key_data can be set"))
+	}
+	hasClientCertificate := c.ClientCertificate != "" || c.ClientCertificateData != ""
+	hasClientKey := c.ClientKey != "" || c.ClientKeyData != ""
+	if hasClientCertificate != hasClientKey {
+		errs = append(errs, fmt.Errorf("client certificate and client key must be set together"))
+	}
+	if c.ProxyURL != "" {
+		if err := validateProxyURL(c.ProxyURL); err != nil {
+			errs = append(errs, fmt.Errorf("proxy_url is invalid: %w", err))
+		}
+	}
+	if c.CompletionCacheTTL != nil && *c.CompletionCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("completion_cache_ttl must not be negative"))
+	}
+	if c.CredentialHelper != nil && c.CredentialHelper.Command == "" {
+		errs = append(errs, fmt.Errorf("credential_helper command must be set"))
+	}
+	if c.Retry != nil {
+		if err := c.Retry.validate(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for flag := range c.Defaults {
+		if flag == "" || flag == "config" {
+			errs = append(errs, fmt.Errorf("defaults contain an invalid flag name: %q", flag))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// SetField sets the context field with the given yaml key to the given value, an empty value unsets the field
+func (c *Context) SetField(field, value string) error {
+	if flag, ok := strings.CutPrefix(field, DefaultsFieldPrefix); ok {
+		if value == "" {
+			delete(c.Defaults, flag)
+			return nil
+		}
+		if c.Defaults == nil {
+			c.Defaults = map[string]string{}
+		}
+		c.Defaults[flag] = value
+		return nil
+	}
+	if table, ok := strings.CutPrefix(field, ColumnsFieldPrefix); ok {
+		if value == "" {
+			delete(c.Columns, table)
+			return nil
+		}
+		if c.Columns == nil {
+			c.Columns = map[string][]string{}
+		}
+		c.Columns[table] = strings.Split(value, ",")
+		return nil
+	}
+	if retryField, ok := strings.CutPrefix(field, RetryFieldPrefix); ok {
+		return c.setRetryField(retryField, value)
+	}
+
+	switch field {
+	case "url":
+		c.ApiURL = value
+	case "certificate_authority_data":
+		c.CertificateAuthorityData = value
+	case "certificate_authority_file":
+		c.CertificateAuthorityFile = value
+	case "client_certificate":
+		c.ClientCertificate = value
+	case "client_certificate_data":
+		c.ClientCertificateData = value
+	case "client_key":
+		c.ClientKey = value
+	case "client_key_data":
+		c.ClientKeyData = value
+	case "insecure_skip_verify":
+		c.InsecureSkipVerify = false
+		if value != "" {
+			insecure, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("insecure_skip_verify %q is not a valid boolean", value)
+			}
+			c.InsecureSkipVerify = insecure
+		}
+	case "tls_server_name":
+		c.TLSServerName = value
+	case "proxy_url":
+		c.ProxyURL = value
+	case "issuer_url":
+		c.IssuerURL = value
+	case "issuer_type":
+		c.IssuerType = value
+	case "custom_scopes":
+		c.CustomScopes = value
+	case "client_id":
+		c.ClientID = value
+	case "client_secret":
+		c.ClientSecret = value
+	case "hmac":
+		c.HMAC = nil
+		if value != "" {
+			c.HMAC = &value
+		}
+	case "hmac_auth_type":
+		c.HMACAuthType = value
+	case "credential_helper":
+		helper, err := parseCredentialHelper(value)
+		if err != nil {
+			return err
+		}
+		c.CredentialHelper = helper
+	case "completion_cache_ttl":
+		c.CompletionCacheTTL = nil
+		if value != "" {
+			ttl, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("completion_cache_ttl %q is not a valid duration", value)
+			}
+			c.CompletionCacheTTL = &ttl
+		}
+	default:
+		return fmt.Errorf("unknown context field %q, must be one of: %s", field, strings.Join(ContextFields, "|"))
+	}
+
+	return nil
+}
+
+// CompletionCacheExpiry returns how long shell completion results are cached, zero disables the cache
+func (c *Context) CompletionCacheExpiry() time.Duration {
+	if c.CompletionCacheTTL == nil {
+		return DefaultCompletionCacheTTL
+	}
+	return *c.CompletionCacheTTL
+}
+
+// Redacted returns a copy of the context with secrets being hidden
+func (c Context) Redacted() Context {
+	if c.ClientSecret != "" {
+		c.ClientSecret = redacted
+	}
+	if c.HMAC != nil {
+		c.HMAC = new(redacted)
+	}
+	if c.ClientKeyData != "" {
+		c.ClientKeyData = redacted
+	}
+	if c.CredentialHelper != nil && len(c.CredentialHelper.Env) > 0 {
+		helper := *c.CredentialHelper
+		helper.Env = map[string]string{}
+		for k := range c.CredentialHelper.Env {
+			helper.Env[k] = redacted
+		}
+		c.CredentialHelper = &helper
+	}
+	return c
+}
+
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host must be set")
+	}
+	return nil
+}
+
+func validateProxyURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains([]string{"http", "https", "socks5"}, u.Scheme) {
+		return fmt.Errorf("scheme must be http, https or socks5")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host must be set")
+	}
+	return nil
+}
@@ -0,0 +1,257 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/metal-stack/metal-lib/pkg/pointer"
+	"github.com/metal-stack/metal-lib/pkg/testcommon"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContext_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     Context
+		wantErr error
+	}{
+		{
+			name: "valid context",
+			ctx: Context{
+				ApiURL:       "https://api.metal-stack.io/metal",
+				IssuerURL:    "https://dex.metal-stack.io/dex",
+				IssuerType:   "generic",
+				HMACAuthType: "Metal-View",
+			},
+		},
+		{
+			name:    "url missing",
+			ctx:     Context{},
+			wantErr: errors.New("url must be set"),
+		},
+		{
+			name: "invalid urls",
+			ctx: Context{
+				ApiURL:    "ftp://api.metal-stack.io",
+				IssuerURL: "https://",
+			},
+			wantErr: errors.New("url is invalid: scheme must be http or https\nissuer_url is invalid: host must be set"),
+		},
+		{
+			name: "invalid issuer and hmac auth type",
+			ctx: Context{
+				ApiURL:       "http://localhost:8080/metal",
+				IssuerType:   "keycloak",
+				HMACAuthType: "Metal-God",
+			},
+			wantErr: errors.New(`issuer_type "keycloak" is invalid, must be one of: dex|generic` + "\n" + `hmac_auth_type "Metal-God" is invalid, must be one of: Metal-Admin|Metal-Edit|Metal-View`),
+		},
+		{
+			name: "invalid tls and proxy settings",
+			ctx: Context{
+				ApiURL:                   "https://api.metal-stack.io/metal",
+				CertificateAuthorityData: "Y2E=",
+				CertificateAuthorityFile: "/etc/ssl/ca.pem",
+				ClientCertificate:        "/etc/ssl/client.pem",
+				ProxyURL:                 "ftp://proxy:21",
+			},
+			wantErr: errors.New("only one of certificate_authority_data and certificate_authority_file can be set\nclient certificate and client key must be set together\nproxy_url is invalid: scheme must be http, https or socks5"),
+		},
+		{
+			name: "invalid retry policy",
+			ctx: Context{
+				ApiURL: "http://localhost:8080/metal",
+				Retry:  &RetryPolicy{MaxRetries: 3, InitialBackoff: time.Minute, MaxBackoff: time.Second},
+			},
+			wantErr: errors.New("retry initial_backoff must not be greater than max_backoff"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ctx.Validate()
+			if tt.wantErr != nil {
+				require.EqualError(t, err, tt.wantErr.Error())
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestContext_SetField(t *testing.T) {
+	tests := []struct {
+		name    string
+		ctx     Context
+		field   string
+		value   string
+		want    Context
+		wantErr error
+	}{
+		{
+			name:  "set url",
+			field: "url",
+			value: "https://api.metal-stack.io/metal",
+			want:  Context{ApiURL: "https://api.metal-stack.io/metal"},
+		},
+		{
+			name:  "set hmac",
+			field: "hmac",
+			value: "secret",
+			want:  Context{HMAC: new("secret")},
+		},
+		{
+			name:  "unset hmac",
+			ctx:   Context{HMAC: new("secret")},
+			field: "hmac",
+			value: "",
+			want:  Context{},
+		},
+		{
+			name:  "set credential helper",
+			field: "credential_helper",
+			value: "pass-helper show metal/prod",
+			want:  Context{CredentialHelper: &CredentialHelper{Command: "pass-helper", Args: []string{"show", "metal/prod"}}},
+		},
+		{
+			name:  "set default",
+			ctx:   Context{Defaults: map[string]string{"project": "a"}},
+			field: "defaults.partition",
+			value: "b",
+			want:  Context{Defaults: map[string]string{"project": "a", "partition": "b"}},
+		},
+		{
+			name:  "unset default",
+			ctx:   Context{Defaults: map[string]string{"project": "a"}},
+			field: "defaults.project",
+			value: "",
+			want:  Context{Defaults: map[string]string{}},
+		},
+		{
+			name:    "unknown field",
+			field:   "foo",
+			value:   "bar",
+			wantErr: errors.New(`unknown context field "foo", must be one of: url|certificate_authority_data|certificate_authority_file|client_certificate|client_certificate_data|client_key|client_key_data|insecure_skip_verify|tls_server_name|proxy_url|issuer_url|issuer_type|custom_scopes|client_id|client_secret|hmac|hmac_auth_type|credential_helper|defaults.<flag>|retry.max_retries|retry.initial_backoff|retry.max_backoff|completion_cache_ttl|columns.<table>`),
+		},
+		{
+			name:  "set insecure skip verify",
+			field: "insecure_skip_verify",
+			value: "true",
+			want:  Context{InsecureSkipVerify: true},
+		},
+		{
+			name:    "invalid insecure skip verify",
+			field:   "insecure_skip_verify",
+			value:   "maybe",
+			wantErr: errors.New(`insecure_skip_verify "maybe" is not a valid boolean`),
+		},
+		{
+			name:  "set retry field",
+			field: "retry.max_retries",
+			value: "0",
+			want:  Context{Retry: &RetryPolicy{MaxRetries: 0, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 10 * time.Second}},
+		},
+		{
+			name:  "reset retry field to default",
+			ctx:   Context{Retry: &RetryPolicy{MaxRetries: 3, InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}},
+			field: "retry.initial_backoff",
+			value: "",
+			want:  Context{},
+		},
+		{
+			name:    "invalid retry backoff",
+			field:   "retry.max_backoff",
+			value:   "foo",
+			wantErr: errors.New(`retry max_backoff "foo" is not a valid duration`),
+			want:    Context{},
+		},
+		{
+			name:    "unknown retry field",
+			field:   "retry.foo",
+			value:   "1",
+			wantErr: errors.New(`unknown retry field "foo", must be one of: max_retries|initial_backoff|max_backoff`),
+		},
+		{
+			name:  "disable completion cache",
+			field: "completion_cache_ttl",
+			value: "0s",
+			want:  Context{CompletionCacheTTL: pointer.Pointer(time.Duration(0))},
+		},
+		{
+			name:  "set columns",
+			field: "columns.machine",
+			value: "id,hostname,rack",
+			want:  Context{Columns: map[string][]string{"machine": {"id", "hostname", "rack"}}},
+		},
+		{
+			name:  "unset columns",
+			ctx:   Context{Columns: map[string][]string{"machine": {"id"}}},
+			field: "columns.machine",
+			value: "",
+			want:  Context{Columns: map[string][]string{}},
+		},
+		{
+			name:    "invalid completion cache ttl",
+			field:   "completion_cache_ttl",
+			value:   "soon",
+			wantErr: errors.New(`completion_cache_ttl "soon" is not a valid duration`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ctx.SetField(tt.field, tt.value)
+			if diff := cmp.Diff(tt.wantErr, err, testcommon.ErrorStringComparer()); diff != "" {
+				t.Errorf("error diff (+got -want):\n %s", diff)
+			}
+			if diff := cmp.Diff(tt.want, tt.ctx); diff != "" {
+				t.Errorf("diff (+got -want):\n %s", diff)
+			}
+		})
+	}
+}
+
+func TestContext_Redacted(t *testing.T) {
+	ctx := Context{
+		ClientSecret: "secret",
+		HMAC:         new("hmac"),
+		CredentialHelper: &CredentialHelper{
+			Command: "pass-helper",
+			Env:     map[string]string{"PASSWORD_STORE_KEY": "secret"},
+		},
+	}
+
+	want := Context{
+		ClientSecret: redacted,
+		HMAC:         new(redacted),
+		CredentialHelper: &CredentialHelper{
+			Command: "pass-helper",
+			Env:     map[string]string{"PASSWORD_STORE_KEY": redacted},
+		},
+	}
+
+	if diff := cmp.Diff(want, ctx.Redacted()); diff != "" {
+		t.Errorf("diff (+got -want):\n %s", diff)
+	}
+	require.Equal(t, "secret", ctx.CredentialHelper.Env["PASSWORD_STORE_KEY"], "the original context must not be modified")
+}
+
+func TestContexts_ActiveContextName(t *testing.T) {
+	defer viper.Reset()
+
+	ctxs := &Contexts{CurrentContext: "prod"}
+
+	viper.Reset()
+	require.Equal(t, "prod", ctxs.ActiveContextName())
+
+	viper.Set("context", "dev")
+	require.Equal(t, "dev", ctxs.ActiveContextName())
+	require.Equal(t, "prod", ctxs.CurrentContext)
+
+	viper.Reset()
+	viper.SetEnvPrefix("metalctl")
+	viper.AutomaticEnv()
+	t.Setenv("METALCTL_CONTEXT", "test")
+	require.Equal(t, "test", ctxs.ActiveContextName())
+}
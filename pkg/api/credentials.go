@@ -0,0 +1,171 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// CredentialHelper is an external command, which prints the credentials for a context as json to stdout.
+// This way secrets do not need to be stored in the config file.
+type CredentialHelper struct {
+	Command string            `json:"command" yaml:"command"`
+	Args    []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+}
+
+// Credentials are returned by a credential helper, they are only kept in memory until they expire
+type Credentials struct {
+	HMAC         string     `json:"hmac,omitempty"`
+	ClientSecret string     `json:"client_secret,omitempty"`
+	Token        string     `json:"token,omitempty"`
+	Expiration   *time.Time `json:"expiration,omitempty"`
+}
+
+// resolvedCredentials holds the credentials of the credential helpers, which already ran in this process,
+// they are never written to disk
+var resolvedCredentials sync.Map
+
+// Credentials returns the credentials resolved before by this process or runs the credential helper
+// in case there are no valid credentials yet
+func (h *CredentialHelper) Credentials() (*Credentials, error) {
+	key, err := h.key()
+	if err != nil {
+		return nil, err
+	}
+
+	if cached, ok := resolvedCredentials.Load(key); ok {
+		creds := cached.(*Credentials)
+		if creds.Expiration == nil || time.Now().Before(*creds.Expiration) {
+			return creds, nil
+		}
+	}
+
+	return h.refresh(key)
+}
+
+// Refresh runs the credential helper again regardless of resolved credentials, e.g. when the metal-api rejects them
+func (h *CredentialHelper) Refresh() (*Credentials, error) {
+	key, err := h.key()
+	if err != nil {
+		return nil, err
+	}
+
+	return h.refresh(key)
+}
+
+func (h *CredentialHelper) refresh(key string) (*Credentials, error) {
+	creds, err := h.run()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvedCredentials.Store(key, creds)
+
+	return creds, nil
+}
+
+func (h *CredentialHelper) run() (*Credentials, error) {
+	var stdout bytes.Buffer
+
+	cmd := exec.Command(h.Command, h.Args...) //nolint:gosec
+	cmd.Env = os.Environ()
+	for k, v := range h.Env {
+		cmd.Env = append(cmd.Env, k+"="+v)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	// the helper can use stderr for interacting with the user, e.g. for unlocking a password store
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %q failed: %w", h.Command, err)
+	}
+
+	var creds Credentials
+	err = json.Unmarshal(stdout.Bytes(), &creds)
+	if err != nil {
+		return nil, fmt.Errorf("credential helper %q returned invalid json: %w", h.Command, err)
+	}
+
+	if creds.HMAC == "" && creds.ClientSecret == "" && creds.Token == "" {
+		return nil, fmt.Errorf("credential helper %q returned no credentials", h.Command)
+	}
+
+	return &creds, nil
+}
+
+// key identifies the helper configuration, such that changing the helper does not return credentials of the former one
+func (h *CredentialHelper) key() (string, error) {
+	raw, err := json.Marshal(h)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parseCredentialHelper parses a credential helper from a command line, an empty command line results in no helper.
+// Arguments are split at whitespace unless quoted with single or double quotes or escaped with a backslash like in a shell.
+func parseCredentialHelper(commandLine string) (*CredentialHelper, error) {
+	var (
+		fields  []string
+		field   strings.Builder
+		inField bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range commandLine {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inField = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			field.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inField = true
+		case unicode.IsSpace(r):
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("credential_helper %q contains an unterminated quote or escape", commandLine)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return &CredentialHelper{
+		Command: fields[0],
+		Args:    fields[1:],
+	}, nil
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/metal-stack/metal-lib/pkg/testcommon"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialHelper_Credentials(t *testing.T) {
+	dir := t.TempDir()
+
+	var (
+		counter    = filepath.Join(dir, "counter")
+		expiration = time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		script     = filepath.Join(dir, "helper.sh")
+	)
+
+	require.NoError(t, os.WriteFile(script, []byte(`#!/bin/sh
+echo run >> "$COUNTER"
+echo '{"hmac": "'"$1"'", "client_secret": "secret", "expiration": "'"$EXPIRATION"'"}'
+`), 0700))
+
+	helper := &CredentialHelper{
+		Command: script,
+		Args:    []string{"my-hmac"},
+		Env: map[string]string{
+			"COUNTER":    counter,
+			"EXPIRATION": expiration.Format(time.RFC3339),
+		},
+	}
+
+	want := &Credentials{
+		HMAC:         "my-hmac",
+		ClientSecret: "secret",
+		Expiration:   &expiration,
+	}
+
+	for range 2 {
+		got, err := helper.Credentials()
+		require.NoError(t, err)
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("diff (+got -want):\n %s", diff)
+		}
+	}
+
+	runs, err := os.ReadFile(counter)
+	require.NoError(t, err)
+	require.Equal(t, "run\n", string(runs), "credentials should have been kept in memory")
+
+	cacheDir, err := os.UserCacheDir()
+	require.NoError(t, err)
+	require.NoDirExists(t, filepath.Join(cacheDir, "metalctl", "credentials"), "credentials must not be written to disk")
+
+	_, err = (&CredentialHelper{Command: "false"}).Credentials()
+	require.EqualError(t, err, `credential helper "false" failed: exit status 1`)
+}
+
+func Test_parseCredentialHelper(t *testing.T) {
+	tests := []struct {
+		name        string
+		commandLine string
+		want        *CredentialHelper
+		wantErr     error
+	}{
+		{
+			name:        "empty",
+			commandLine: "  ",
+			want:        nil,
+		},
+		{
+			name:        "plain arguments",
+			commandLine: "pass-helper show  metal/prod",
+			want:        &CredentialHelper{Command: "pass-helper", Args: []string{"show", "metal/prod"}},
+		},
+		{
+			name:        "quoted arguments",
+			commandLine: `op read "op://metal stack/prod/hmac" --account 'my team' empty="" escaped\ space`,
+			want:        &CredentialHelper{Command: "op", Args: []string{"read", "op://metal stack/prod/hmac", "--account", "my team", "empty=", "escaped space"}},
+		},
+		{
+			name:        "unterminated quote",
+			commandLine: `op read "op://metal`,
+			wantErr:     fmt.Errorf(`credential_helper "op read \"op://metal" contains an unterminated quote or escape`),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseCredentialHelper(tt.commandLine)
+			if diff := cmp.Diff(tt.wantErr, err, testcommon.ErrorStringComparer()); diff != "" {
+				t.Errorf("error diff (+got -want):\n %s", diff)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("diff (+got -want):\n %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/metal-stack/metal-lib/auth"
+	"golang.org/x/oauth2"
+)
+
+// DeviceCodeConfig contains the parameters for the oauth2 device authorization grant
+type DeviceCodeConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	TokenHandler auth.TokenHandlerFunc
+
+	// Console receives the verification url and the user code
+	Console io.Writer
+	// HTTPClient is used for the communication with the issuer, defaults to http.DefaultClient
+	HTTPClient *http.Client
+}
+
+// DeviceCodeFlow runs the oauth2 device authorization grant (RFC 8628) against the given issuer.
+// It prints the verification url and the user code to the console and polls the issuer until the
+// user has granted access. The received id token is verified and passed to the token handler.
+func DeviceCodeFlow(ctx context.Context, config DeviceCodeConfig) error {
+	if config.IssuerURL == "" {
+		return fmt.Errorf("no issuer url configured in context")
+	}
+	if config.ClientID == "" {
+		return fmt.Errorf("no client id configured in context")
+	}
+	if config.HTTPClient != nil {
+		ctx = oidc.ClientContext(ctx, config.HTTPClient)
+	}
+
+	provider, err := oidc.NewProvider(ctx, config.IssuerURL)
+	if err != nil {
+		return fmt.Errorf("unable to discover issuer %s: %w", config.IssuerURL, err)
+	}
+
+	endpoint := provider.Endpoint()
+	if endpoint.DeviceAuthURL == "" {
+		return fmt.Errorf("issuer %s does not support the device authorization grant", config.IssuerURL)
+	}
+
+	oauth2Config := oauth2.Config{
+		ClientID:     config.ClientID,
+		ClientSecret: config.ClientSecret,
+		Endpoint:     endpoint,
+		Scopes:       config.Scopes,
+	}
+
+	deviceAuth, err := oauth2Config.DeviceAuth(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to request device code: %w", err)
+	}
+
+	if config.Console != nil {
+		verificationURL := deviceAuth.VerificationURI
+		if deviceAuth.VerificationURIComplete != "" {
+			verificationURL = deviceAuth.VerificationURIComplete
+		}
+		_, _ = fmt.Fprintf(config.Console, "Please visit %s and enter the code: %s\n", verificationURL, deviceAuth.UserCode)
+		_, _ = fmt.Fprintln(config.Console, "Waiting for authorization...")
+	}
+
+	token, err := oauth2Config.DeviceAccessToken(ctx, deviceAuth)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve token: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return fmt.Errorf("no id_token in token response")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: config.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return fmt.Errorf("failed to verify id token: %w", err)
+	}
+
+	var rawClaims json.RawMessage
+	err = idToken.Claims(&rawClaims)
+	if err != nil {
+		return fmt.Errorf("failed to parse claims: %w", err)
+	}
+
+	var claims auth.Claims
+	err = json.Unmarshal(rawClaims, &claims)
+	if err != nil {
+		return fmt.Errorf("failed to read claims: %w", err)
+	}
+
+	return config.TokenHandler(auth.TokenInfo{
+		IDToken:      rawIDToken,
+		RefreshToken: token.RefreshToken,
+		TokenClaims:  claims,
+		IssuerConfig: auth.IssuerConfig{
+			ClientID:     config.ClientID,
+			ClientSecret: config.ClientSecret,
+			IssuerURL:    config.IssuerURL,
+		},
+	})
+}
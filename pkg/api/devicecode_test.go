@@ -0,0 +1,210 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/metal-stack/metal-lib/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssuer implements the parts of an oidc issuer required for the device authorization grant,
+// the client credentials grant and the token refresh
+type fakeIssuer struct {
+	*httptest.Server
+
+	key      *rsa.PrivateKey
+	clientID string
+	// pending is the amount of token requests answered with authorization_pending
+	pending int32
+	// polls counts the device code and client credentials token requests
+	polls atomic.Int32
+	// expiry of the issued id tokens relative to now
+	expiry time.Duration
+}
+
+func newFakeIssuer(t *testing.T, clientID string, pending int32) *fakeIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	f := &fakeIssuer{
+		key:      key,
+		clientID: clientID,
+		pending:  pending,
+		expiry:   time.Hour,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":                                f.URL,
+			"authorization_endpoint":                f.URL + "/auth",
+			"device_authorization_endpoint":         f.URL + "/device/code",
+			"token_endpoint":                        f.URL + "/token",
+			"jwks_uri":                              f.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("GET /keys", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, jose.JSONWebKeySet{
+			Keys: []jose.JSONWebKey{{Key: &f.key.PublicKey, KeyID: "test", Algorithm: "RS256", Use: "sig"}},
+		})
+	})
+	mux.HandleFunc("POST /device/code", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != f.clientID {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"device_code":      "device-code",
+			"user_code":        "ABCD-EFGH",
+			"verification_uri": f.URL + "/device",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("grant_type") == "client_credentials" {
+			clientID, clientSecret, ok := r.BasicAuth()
+			if !ok || clientID != f.clientID || clientSecret != "secret" {
+				writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+				return
+			}
+			f.polls.Add(1)
+			writeJSON(w, http.StatusOK, map[string]any{
+				"access_token": f.idToken(t),
+				"token_type":   "bearer",
+				"expires_in":   int(f.expiry.Seconds()),
+				"scope":        r.FormValue("scope"),
+			})
+			return
+		}
+		if r.FormValue("grant_type") == "refresh_token" {
+			if r.FormValue("refresh_token") != "refresh-token" {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]any{
+				"access_token":  "access-token",
+				"token_type":    "bearer",
+				"refresh_token": "rotated-refresh-token",
+				"expires_in":    3600,
+				"id_token":      f.idToken(t),
+			})
+			return
+		}
+		if r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" || r.FormValue("device_code") != "device-code" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		if f.polls.Add(1) <= f.pending {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "authorization_pending"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"access_token":  "access-token",
+			"token_type":    "bearer",
+			"refresh_token": "refresh-token",
+			"expires_in":    3600,
+			"id_token":      f.idToken(t),
+		})
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+
+	return f
+}
+
+func (f *fakeIssuer) idToken(t *testing.T) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: f.key}, (&jose.SignerOptions{}).WithHeader("kid", "test"))
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(map[string]any{
+		"iss":   f.URL,
+		"sub":   "1234",
+		"aud":   f.clientID,
+		"exp":   time.Now().Add(f.expiry).Unix(),
+		"iat":   time.Now().Unix(),
+		"name":  "Jane Doe",
+		"email": "jane@metal-stack.io",
+	})
+	require.NoError(t, err)
+
+	jws, err := signer.Sign(payload)
+	require.NoError(t, err)
+
+	token, err := jws.CompactSerialize()
+	require.NoError(t, err)
+
+	return token
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestDeviceCodeFlow(t *testing.T) {
+	issuer := newFakeIssuer(t, "metalctl", 1)
+
+	var (
+		console bytes.Buffer
+		got     auth.TokenInfo
+	)
+
+	err := DeviceCodeFlow(context.Background(), DeviceCodeConfig{
+		IssuerURL: issuer.URL,
+		ClientID:  "metalctl",
+		Scopes:    auth.GenericScopes,
+		TokenHandler: func(tokenInfo auth.TokenInfo) error {
+			got = tokenInfo
+			return nil
+		},
+		Console:    &console,
+		HTTPClient: issuer.Client(),
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, console.String(), "Please visit "+issuer.URL+"/device and enter the code: ABCD-EFGH")
+	assert.Equal(t, int32(2), issuer.polls.Load())
+	assert.NotEmpty(t, got.IDToken)
+	assert.Equal(t, "refresh-token", got.RefreshToken)
+	assert.Equal(t, "jane@metal-stack.io", got.TokenClaims.EMail)
+	assert.Equal(t, "Jane Doe", got.TokenClaims.Username())
+	assert.Equal(t, issuer.URL, got.IssuerURL)
+	assert.Equal(t, "metalctl", got.ClientID)
+}
+
+func TestDeviceCodeFlowUnsupportedIssuer(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"issuer":         "http://" + r.Host,
+			"token_endpoint": "http://" + r.Host + "/token",
+			"jwks_uri":       "http://" + r.Host + "/keys",
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	err := DeviceCodeFlow(context.Background(), DeviceCodeConfig{
+		IssuerURL: server.URL,
+		ClientID:  "metalctl",
+		TokenHandler: func(tokenInfo auth.TokenInfo) error {
+			return nil
+		},
+	})
+	require.EqualError(t, err, "issuer "+server.URL+" does not support the device authorization grant")
+}
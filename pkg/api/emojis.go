@@ -11,6 +11,19 @@ const (
 	VPN         = "🛡"
 )
 
+// textual status tokens, which replace the emojis and colors in plain mode
+const (
+	FailedReclaimToken  = "FAILED-RECLAIM"
+	LastEventErrorToken = "LAST-EVENT-ERROR"
+	ReservedToken       = "RESERVED"
+	CrashLoopToken      = "CRASHLOOP"
+	LockedToken         = "LOCKED"
+	UnknownToken        = "UNKNOWN"
+	DeadToken           = "DEAD"
+	VPNToken            = "VPN"
+	LEDOnToken          = "LED-ON"
+)
+
 func EmojiHelpText() string {
 	return `
 Meaning of the emojis:
@@ -23,5 +36,24 @@ Meaning of the emojis:
 ⭕ Machine is in a provisioning crash loop. Flag can be reset through an API-triggered reboot or when the machine reaches the phoned home state.
 🚑 Machine reclaim has failed. The machine was deleted but it is not going back into the available machine pool.
 🛡 Machine is connected to our VPN, ssh access only possible via this VPN.
+
+Machine IDs are printed in blue when the LED of the machine is turned on.
+` + PlainHelpText()
+}
+
+// PlainHelpText describes the status tokens, which are printed instead of the emojis with --plain
+func PlainHelpText() string {
+	return `
+Meaning of the status tokens with --plain:
+
+RESERVED          Machine is reserved.
+LOCKED            Machine is locked.
+DEAD              Machine is dead.
+LAST-EVENT-ERROR  Machine has a last event error.
+UNKNOWN           Machine is in unknown condition.
+CRASHLOOP         Machine is in a provisioning crash loop.
+FAILED-RECLAIM    Machine reclaim has failed.
+VPN               Machine is connected to our VPN.
+LED-ON            The LED of the machine is turned on.
 `
 }
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/metal-stack/metal-lib/auth"
+	"github.com/metal-stack/metal-lib/jwt/sec"
+	"golang.org/x/oauth2"
+)
+
+// tokenExpiryLeeway is subtracted from the token expiry to compensate clock skew and request durations
+const tokenExpiryLeeway = 30 * time.Second
+
+// TokenExpired returns true if the given token is expired or expires within a short leeway
+func TokenExpired(token string) bool {
+	_, claims, err := sec.ParseTokenUnvalidatedUnfiltered(token)
+	if err != nil || claims.ExpiresAt == 0 {
+		return false
+	}
+	return time.Unix(claims.ExpiresAt, 0).Add(-tokenExpiryLeeway).Before(time.Now())
+}
+
+// RefreshToken exchanges the refresh token of the given auth context in the kubeconfig for a new id token
+// at the issuer and writes the result back into the kubeconfig.
+// The http client is used for the communication with the issuer and may be nil.
+func RefreshToken(ctx context.Context, kubeconfig string, authContext *auth.AuthContext, client *http.Client) (string, error) {
+	refreshToken, err := readRefreshToken(kubeconfig, authContext.User)
+	if err != nil {
+		return "", err
+	}
+	if refreshToken == "" {
+		return "", fmt.Errorf("no refresh token stored for user %s, please run metalctl login", authContext.User)
+	}
+
+	if client != nil {
+		ctx = oidc.ClientContext(ctx, client)
+	}
+
+	provider, err := oidc.NewProvider(ctx, authContext.IssuerURL)
+	if err != nil {
+		return "", fmt.Errorf("unable to discover issuer %s: %w", authContext.IssuerURL, err)
+	}
+
+	oauth2Config := oauth2.Config{
+		ClientID:     authContext.ClientID,
+		ClientSecret: authContext.ClientSecret,
+		Endpoint:     provider.Endpoint(),
+	}
+
+	token, err := oauth2Config.TokenSource(ctx, &oauth2.Token{
+		RefreshToken: refreshToken,
+		Expiry:       time.Now().Add(-time.Hour),
+	}).Token()
+	if err != nil {
+		return "", fmt.Errorf("unable to refresh token: %w", err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", fmt.Errorf("no id_token in token response")
+	}
+
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: authContext.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to verify id token: %w", err)
+	}
+
+	var claims auth.Claims
+	err = idToken.Claims(&claims)
+	if err != nil {
+		return "", fmt.Errorf("failed to read claims: %w", err)
+	}
+
+	// issuers are not obliged to rotate the refresh token
+	if token.RefreshToken != "" {
+		refreshToken = token.RefreshToken
+	}
+
+	_, err = auth.UpdateKubeConfigContext(kubeconfig, auth.TokenInfo{
+		IDToken:      rawIDToken,
+		RefreshToken: refreshToken,
+		TokenClaims:  claims,
+		IssuerConfig: authContext.IssuerConfig,
+	}, func(auth.TokenInfo) string {
+		// keep the user name the context already refers to
+		return authContext.User
+	}, authContext.Ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to write refreshed token to kubeconfig: %w", err)
+	}
+
+	return rawIDToken, nil
+}
+
+// readRefreshToken reads the refresh token of the given user from the kubeconfig,
+// which is not contained in the auth context
+func readRefreshToken(kubeconfig, userName string) (string, error) {
+	cfg, _, _, err := auth.LoadKubeConfig(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+
+	users, _ := cfg["users"].([]any)
+	for _, u := range users {
+		user := toStringMap(u)
+		if user["name"] != userName {
+			continue
+		}
+
+		config := toStringMap(toStringMap(toStringMap(user["user"])["auth-provider"])["config"])
+		refreshToken, _ := config["refresh-token"].(string)
+
+		return refreshToken, nil
+	}
+
+	return "", fmt.Errorf("user %s not found in kubeconfig", userName)
+}
+
+func toStringMap(v any) map[string]any {
+	switch m := v.(type) {
+	case map[string]any:
+		return m
+	case map[any]any:
+		res := map[string]any{}
+		for k, v := range m {
+			res[fmt.Sprintf("%v", k)] = v
+		}
+		return res
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/metal-stack/metal-lib/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshToken(t *testing.T) {
+	issuer := newFakeIssuer(t, "metalctl", 0)
+
+	kubeconfig := filepath.Join(t.TempDir(), "kubeconfig")
+	_, err := auth.UpdateKubeConfigContext(kubeconfig, auth.TokenInfo{
+		IDToken:      "expired-token",
+		RefreshToken: "refresh-token",
+		TokenClaims:  auth.Claims{Issuer: issuer.URL},
+		IssuerConfig: auth.IssuerConfig{
+			ClientID:  "metalctl",
+			IssuerURL: issuer.URL,
+		},
+	}, func(auth.TokenInfo) string { return "jane" }, "cloudctl-test")
+	require.NoError(t, err)
+
+	authContext, err := auth.GetAuthContext(kubeconfig, "cloudctl-test")
+	require.NoError(t, err)
+
+	token, err := RefreshToken(context.Background(), kubeconfig, &authContext, issuer.Client())
+	require.NoError(t, err)
+	assert.NotEqual(t, "expired-token", token)
+	assert.False(t, TokenExpired(token))
+
+	authContext, err = auth.GetAuthContext(kubeconfig, "cloudctl-test")
+	require.NoError(t, err)
+	assert.Equal(t, token, authContext.IDToken)
+	assert.Equal(t, "jane", authContext.User)
+
+	refreshToken, err := readRefreshToken(kubeconfig, "jane")
+	require.NoError(t, err)
+	assert.Equal(t, "rotated-refresh-token", refreshToken)
+
+	// the rotated refresh token is not known to the issuer
+	_, err = RefreshToken(context.Background(), kubeconfig, &authContext, issuer.Client())
+	require.ErrorContains(t, err, "invalid_grant")
+}
+
+func TestTokenExpired(t *testing.T) {
+	issuer := newFakeIssuer(t, "metalctl", 0)
+
+	assert.False(t, TokenExpired(issuer.idToken(t)))
+	assert.False(t, TokenExpired("not-a-jwt"))
+
+	issuer.expiry = -time.Minute
+	assert.True(t, TokenExpired(issuer.idToken(t)))
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy configures how often idempotent requests against the metal-api are retried on transient errors
+type RetryPolicy struct {
+	// MaxRetries is the amount of retries after the initial attempt, zero disables retries
+	MaxRetries int `json:"max_retries" yaml:"max_retries"`
+	// InitialBackoff is the wait duration before the first retry, it doubles with every further retry
+	InitialBackoff time.Duration `json:"initial_backoff,omitempty" yaml:"initial_backoff,omitempty"`
+	// MaxBackoff limits the wait duration between two attempts
+	MaxBackoff time.Duration `json:"max_backoff,omitempty" yaml:"max_backoff,omitempty"`
+}
+
+// RetryFieldPrefix is the prefix for setting a field of the retry policy of a context through SetField
+const RetryFieldPrefix = "retry."
+
+// DefaultRetryPolicy is used for contexts without a retry policy
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// RetryPolicy returns the retry policy of the context, missing values are taken from the default policy
+func (c *Context) RetryPolicy() RetryPolicy {
+	if c.Retry == nil {
+		return DefaultRetryPolicy
+	}
+
+	policy := *c.Retry
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+
+	return policy
+}
+
+// Backoff returns the wait duration before the given retry, starting at zero.
+// The duration grows exponentially and is randomized with equal jitter, i.e. it is between half and the full duration,
+// to spread retries of concurrent clients.
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	backoff := p.MaxBackoff
+	if retry < 32 {
+		backoff = min(p.InitialBackoff<<retry, p.MaxBackoff)
+	}
+	if backoff <= 0 {
+		return 0
+	}
+
+	return backoff/2 + rand.N(backoff/2+1)
+}
+
+func (p RetryPolicy) validate() error {
+	if p.MaxRetries < 0 {
+		return fmt.Errorf("retry max_retries must not be negative")
+	}
+	if p.InitialBackoff < 0 || p.MaxBackoff < 0 {
+		return fmt.Errorf("retry backoff must not be negative")
+	}
+	if p.InitialBackoff > 0 && p.MaxBackoff > 0 && p.InitialBackoff > p.MaxBackoff {
+		return fmt.Errorf("retry initial_backoff must not be greater than max_backoff")
+	}
+	return nil
+}
+
+// setRetryField sets a field of the retry policy, an empty value resets the field to its default
+func (c *Context) setRetryField(field, value string) error {
+	policy := DefaultRetryPolicy
+	if c.Retry != nil {
+		policy = *c.Retry
+	}
+
+	switch field {
+	case "max_retries":
+		policy.MaxRetries = DefaultRetryPolicy.MaxRetries
+		if value != "" {
+			retries, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("retry max_retries %q is not a valid number", value)
+			}
+			policy.MaxRetries = retries
+		}
+	case "initial_backoff":
+		backoff, err := parseBackoff(field, value, DefaultRetryPolicy.InitialBackoff)
+		if err != nil {
+			return err
+		}
+		policy.InitialBackoff = backoff
+	case "max_backoff":
+		backoff, err := parseBackoff(field, value, DefaultRetryPolicy.MaxBackoff)
+		if err != nil {
+			return err
+		}
+		policy.MaxBackoff = backoff
+	default:
+		return fmt.Errorf("unknown retry field %q, must be one of: %s", field, strings.Join(retryFields, "|"))
+	}
+
+	c.Retry = &policy
+	if policy == DefaultRetryPolicy {
+		c.Retry = nil
+	}
+
+	return nil
+}
+
+func parseBackoff(field, value string, defaultBackoff time.Duration) (time.Duration, error) {
+	if value == "" {
+		return defaultBackoff, nil
+	}
+	backoff, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("retry %s %q is not a valid duration", field, value)
+	}
+	return backoff, nil
+}
+
+var retryFields = []string{"max_retries", "initial_backoff", "max_backoff"}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{MaxRetries: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+
+	for _, tt := range []struct {
+		retry int
+		min   time.Duration
+		max   time.Duration
+	}{
+		{retry: 0, min: 500 * time.Millisecond, max: time.Second},
+		{retry: 1, min: time.Second, max: 2 * time.Second},
+		{retry: 2, min: 2 * time.Second, max: 4 * time.Second},
+		{retry: 3, min: 2500 * time.Millisecond, max: 5 * time.Second},
+		{retry: 100, min: 2500 * time.Millisecond, max: 5 * time.Second},
+	} {
+		for range 20 {
+			got := policy.Backoff(tt.retry)
+			assert.GreaterOrEqual(t, got, tt.min, "retry %d", tt.retry)
+			assert.LessOrEqual(t, got, tt.max, "retry %d", tt.retry)
+		}
+	}
+}
+
+func TestContext_RetryPolicy(t *testing.T) {
+	var ctx Context
+	assert.Equal(t, DefaultRetryPolicy, ctx.RetryPolicy())
+
+	err := yaml.Unmarshal([]byte("url: http://localhost:8080/metal\nretry:\n  max_retries: 5\n  max_backoff: 30s\n"), &ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, RetryPolicy{MaxRetries: 5, InitialBackoff: 500 * time.Millisecond, MaxBackoff: 30 * time.Second}, ctx.RetryPolicy())
+}
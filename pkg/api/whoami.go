@@ -0,0 +1,17 @@
+package api
+
+import (
+	"time"
+)
+
+// WhoAmI describes the user metalctl authenticates with
+type WhoAmI struct {
+	User              string    `json:"user" yaml:"user"`
+	Tenant            string    `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+	Issuer            string    `json:"issuer,omitempty" yaml:"issuer,omitempty"`
+	Groups            []string  `json:"groups,omitempty" yaml:"groups,omitempty"`
+	ExpiresAt         time.Time `json:"expires_at" yaml:"expires_at"`
+	RemainingValidity string    `json:"remaining_validity" yaml:"remaining_validity"`
+	Context           string    `json:"context,omitempty" yaml:"context,omitempty"`
+	APIURL            string    `json:"api_url,omitempty" yaml:"api_url,omitempty"`
+}